@@ -27,14 +27,42 @@ var (
 		"Path under which to expose metrics.",
 	).Default("/bigip").String()
 	configFile = kingpin.Flag("config.file", "Path to configuration file.").Default("bigip-exporter.yml").String()
+	webConfigFile = kingpin.Flag(
+		"web.config.file",
+		"Path to configuration file that enables TLS, basic authentication and reload restrictions.",
+	).Default("").String()
+	collectPersistence = kingpin.Flag(
+		"collector.persistence",
+		"Collect persistence record and connection table summaries. Expensive on busy devices.",
+	).Default("false").Bool()
+	persistenceMaxRows = kingpin.Flag(
+		"collector.persistence.max-rows",
+		"Maximum number of persistence records and connections read per scrape.",
+	).Default("10000").Int()
+	configCheck = kingpin.Flag(
+		"config.check",
+		"Validate the configuration file and exit.",
+	).Default("false").Bool()
 	sc         = &SafeConfig{
 		C: &Config{},
 	}
 	reloadCh chan chan error
+
+	loginFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "bigip_exporter",
+			Name:      "login_failures_total",
+			Help:      "Number of failed token requests to a BIG-IP login provider.",
+		},
+		[]string{"target", "login_provider", "reason"},
+	)
 )
 
 func init() {
 	prometheus.MustRegister(version.NewCollector("bigip_exporter"))
+	prometheus.MustRegister(configReloadSuccess)
+	prometheus.MustRegister(configReloadSeconds)
+	prometheus.MustRegister(loginFailures)
 }
 
 // define new http handleer
@@ -49,23 +77,50 @@ func newHandler() http.HandlerFunc {
 		var targetCredentials Credentials
 		var err error
 		if targetCredentials, err = sc.CredentialsForTarget(target); err != nil {
-			log.Fatalf("Error getting credentialfor target %s file: %s", target, err)
+			log.Warnf("Error getting credentials for target %s: %s", target, err)
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
 		}
 		user := targetCredentials.User
 		password := targetCredentials.Password
 		basicauth :=targetCredentials.BasicAuth
+		loginProvider := targetCredentials.LoginProvider
 
 		var exporterPartitionsList []string  = nil
 		 
  
-		authMethod := f5.TOKEN
-		if basicauth {
-			authMethod = f5.BASIC_AUTH
+		// The f5 session is never left to log in itself, it either uses
+		// basic auth or sends the token shared with client.
+		bigip := f5.New(target, user, password, f5.BASIC_AUTH)
+		client := collector.NewClient(target, user, password, basicauth, loginProvider)
+		if !basicauth {
+			// The f5 package always logs in with the local tmos provider and
+			// would hold a token of its own, so request one token ourselves
+			// and share it with its session.
+			token, err := client.Token()
+			if err != nil {
+				provider := loginProvider
+				if provider == "" {
+					provider = collector.DefaultLoginProvider
+				}
+				reason := "error"
+				if _, ok := err.(*collector.LoginError); ok {
+					reason = "rejected"
+				}
+				loginFailures.WithLabelValues(target, provider, reason).Inc()
+				log.Errorf("Error logging in to target %s with provider %s: %s", target, provider, err)
+				http.Error(w, fmt.Sprintf("login to %s failed: %s", target, err), http.StatusBadGateway)
+				return
+			}
+			bigip.Session.Userinfo = nil
+			bigip.Session.Header = &http.Header{}
+			bigip.Session.Header.Set("X-F5-Auth-Token", token)
 		}
-		
-		bigip := f5.New(target, user, password, authMethod)
 		Namespace :=  "bigip"
-		bigipCollector, _ := collector.NewBigipCollector(bigip, Namespace, exporterPartitionsList)
+		bigipCollector, _ := collector.NewBigipCollector(bigip, client, Namespace, exporterPartitionsList, collector.Options{
+			Persistence:        *collectPersistence,
+			PersistenceMaxRows: *persistenceMaxRows,
+		})
 
 	
 		registry := prometheus.NewRegistry()
@@ -96,17 +151,63 @@ func updateConfiguration(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// showCredentialsMatch reports which credentials rule applies to a target,
+// without revealing the password. It is subject to the same restrictions as
+// /-/reload, as it reveals the user.
+func showCredentialsMatch(w http.ResponseWriter, r *http.Request) {
+	target := r.URL.Query().Get("target")
+	if target == "" {
+		http.Error(w, "'target' parameter must be specified", 400)
+		return
+	}
+	rule, err := sc.ruleForTarget(target)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	fmt.Fprintf(w, "target: %s\nrule: %s\ntype: %s\nuser: %s\nbasic_auth: %t\nlogin_provider: %s\n",
+		target, rule.key, rule.kind, rule.credentials.User, rule.credentials.BasicAuth, rule.credentials.LoginProvider)
+}
+
 func main() {
 	// Parse flags.
 	log.AddFlags(kingpin.CommandLine)
 	kingpin.Version(version.Print("bigip_exporter"))
 	kingpin.HelpFlag.Short('h')
 	kingpin.Parse()
+	if *persistenceMaxRows <= 0 {
+		kingpin.Fatalf("--collector.persistence.max-rows must be positive, got %d", *persistenceMaxRows)
+	}
+
+	if *configCheck {
+		if _, err := LoadConfig(*configFile); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", *configFile, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s: config is valid\n", *configFile)
+		if *webConfigFile != "" {
+			if _, err := LoadWebConfig(*webConfigFile); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", *webConfigFile, err)
+				os.Exit(1)
+			}
+			fmt.Printf("%s: config is valid\n", *webConfigFile)
+		}
+		os.Exit(0)
+	}
 
 	if err := sc.ReloadConfig(*configFile); err != nil {
 		log.Fatalf("Error parsing config file: %s", err)
 	}
 
+	webConfig, err := LoadWebConfig(*webConfigFile)
+	if err != nil {
+		log.Fatalf("Error parsing web config file: %s", err)
+	}
+	tlsConfig, err := webConfig.TLSConfig()
+	if err != nil {
+		log.Fatalf("Error setting up TLS: %s", err)
+	}
+
 	// landingPage contains the HTML served at '/'.
 	// TODO: Make this nicer and more informative.
 	var landingPage = []byte(`<html>
@@ -125,7 +226,7 @@ func main() {
 
 
 	// load config  first time
-	hup := make(chan os.Signal)
+	hup := make(chan os.Signal, 1)
 	reloadCh = make(chan chan error)
 	signal.Notify(hup, syscall.SIGHUP)
 
@@ -149,11 +250,21 @@ func main() {
 
 	http.Handle("/metrics", promhttp.Handler())
 	http.HandleFunc(*metricPath, prometheus.InstrumentHandlerFunc("metrics", newHandler()))
-	http.HandleFunc("/-/reload", updateConfiguration) // reload config
+	http.Handle("/-/reload", webConfig.restrictReload(http.HandlerFunc(updateConfiguration))) // reload config
+	http.Handle("/-/credentials", webConfig.restrictReload(http.HandlerFunc(showCredentialsMatch)))
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write(landingPage)
 	})
 
+	server := &http.Server{
+		Addr:      *listenAddress,
+		Handler:   webConfig.authenticate(http.DefaultServeMux),
+		TLSConfig: tlsConfig,
+	}
+	if tlsConfig != nil {
+		log.Infoln("Listening on", *listenAddress, "with TLS")
+		log.Fatal(server.ListenAndServeTLS("", ""))
+	}
 	log.Infoln("Listening on", *listenAddress)
-	log.Fatal(http.ListenAndServe(*listenAddress, nil))
+	log.Fatal(server.ListenAndServe())
 }
@@ -0,0 +1,258 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// An AccelerationProfileCollector implements the prometheus.Collector.
+type AccelerationProfileCollector struct {
+	compressionMetrics      map[string]statsMetric
+	webAccelerationMetrics  map[string]statsMetric
+	oneConnectMetrics       map[string]statsMetric
+	client                  *Client
+	partitionsList          []string
+	collectorScrapeStatus   *prometheus.GaugeVec
+	collectorScrapeDuration *prometheus.SummaryVec
+}
+
+// NewAccelerationProfileCollector returns a collector that collecting http compression, web acceleration and one connect profile statistics
+func NewAccelerationProfileCollector(client *Client, namespace string, partitionsList []string) (*AccelerationProfileCollector, error) {
+	var (
+		subsystem  = "http_compression_profile"
+		labelNames = []string{"partition", "profile"}
+	)
+	compressionMetrics := map[string]statsMetric{
+		"preCompressBytes": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "pre_compress_bytes"),
+				"pre_compress_bytes",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("preCompressBytes")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"postCompressBytes": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "post_compress_bytes"),
+				"post_compress_bytes",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("postCompressBytes")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"nullCompressBytes": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "null_compress_bytes"),
+				"null_compress_bytes",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("nullCompressBytes")
+			},
+			valueType: prometheus.CounterValue,
+		},
+	}
+
+	subsystem = "web_acceleration_profile"
+	webAccelerationMetrics := map[string]statsMetric{
+		"cacheHits": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "cache_hits"),
+				"cache_hits",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("cacheHits")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"cacheMisses": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "cache_misses"),
+				"cache_misses",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("cacheMisses")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"cacheEvictions": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "cache_evictions"),
+				"cache_evictions",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("cacheEvictions")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"cacheEntries": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "cache_entries"),
+				"cache_entries",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("cacheEntries")
+			},
+			valueType: prometheus.GaugeValue,
+		},
+	}
+
+	subsystem = "one_connect_profile"
+	oneConnectMetrics := map[string]statsMetric{
+		"curSize": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "idle_connections"),
+				"idle_connections",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("curSize")
+			},
+			valueType: prometheus.GaugeValue,
+		},
+		"maxSize": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "max_idle_connections"),
+				"max_idle_connections",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("maxSize")
+			},
+			valueType: prometheus.GaugeValue,
+		},
+		"reuses": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "reuses"),
+				"reuses",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("reuses")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"connects": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "connects"),
+				"connects",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("connects")
+			},
+			valueType: prometheus.CounterValue,
+		},
+	}
+
+	return &AccelerationProfileCollector{
+		compressionMetrics:     compressionMetrics,
+		webAccelerationMetrics: webAccelerationMetrics,
+		oneConnectMetrics:      oneConnectMetrics,
+		collectorScrapeStatus: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_status",
+				Help:      "collector_scrape_status",
+			},
+			[]string{"collector"},
+		),
+		collectorScrapeDuration: prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_duration",
+				Help:      "collector_scrape_duration",
+			},
+			[]string{"collector"},
+		),
+		client:         client,
+		partitionsList: partitionsList,
+	}, nil
+}
+
+// Collect collects metrics for BIG-IP http compression, web acceleration and
+// one connect profiles.
+func (c *AccelerationProfileCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	compressionErr := c.collectProfiles(ch, "/mgmt/tm/ltm/profile/http-compression/stats", c.compressionMetrics)
+	if compressionErr != nil {
+		logger.Warningf("Failed to get statistics for http compression profiles (%s)", compressionErr)
+	}
+	webAccelerationErr := c.collectProfiles(ch, "/mgmt/tm/ltm/profile/web-acceleration/stats", c.webAccelerationMetrics)
+	if webAccelerationErr != nil {
+		logger.Warningf("Failed to get statistics for web acceleration profiles (%s)", webAccelerationErr)
+	}
+	oneConnectErr := c.collectProfiles(ch, "/mgmt/tm/ltm/profile/one-connect/stats", c.oneConnectMetrics)
+	if oneConnectErr != nil {
+		logger.Warningf("Failed to get statistics for one connect profiles (%s)", oneConnectErr)
+	}
+	if compressionErr != nil || webAccelerationErr != nil || oneConnectErr != nil {
+		c.collectorScrapeStatus.WithLabelValues("acceleration_profile").Set(float64(0))
+	} else {
+		c.collectorScrapeStatus.WithLabelValues("acceleration_profile").Set(float64(1))
+		logger.Debugf("Successfully fetched statistics for http compression, web acceleration and one connect profiles")
+	}
+
+	elapsed := time.Since(start)
+	c.collectorScrapeDuration.WithLabelValues("acceleration_profile").Observe(float64(elapsed.Seconds()))
+	c.collectorScrapeStatus.Collect(ch)
+	c.collectorScrapeDuration.Collect(ch)
+	logger.Debugf("Getting http compression, web acceleration and one connect profile statistics took %s", elapsed)
+}
+
+func (c *AccelerationProfileCollector) collectProfiles(ch chan<- prometheus.Metric, path string, metrics map[string]statsMetric) error {
+	var allProfileStats statsResponse
+	if err := c.client.get(path, &allProfileStats); err != nil {
+		return err
+	}
+	for _, profileStats := range allProfileStats.Entries {
+		entries := profileStats.NestedStats.Entries
+		partition, profileName := splitFullPath(entries.description("tmName"))
+
+		if c.partitionsList != nil && !stringInSlice(partition, c.partitionsList) {
+			continue
+		}
+
+		labels := []string{partition, profileName}
+		for _, metric := range metrics {
+			ch <- prometheus.MustNewConstMetric(metric.desc, metric.valueType, metric.extract(entries), labels...)
+		}
+	}
+	return nil
+}
+
+// Describe describes the metrics exported from this collector.
+func (c *AccelerationProfileCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, metric := range c.compressionMetrics {
+		ch <- metric.desc
+	}
+	for _, metric := range c.webAccelerationMetrics {
+		ch <- metric.desc
+	}
+	for _, metric := range c.oneConnectMetrics {
+		ch <- metric.desc
+	}
+	c.collectorScrapeStatus.Describe(ch)
+	c.collectorScrapeDuration.Describe(ch)
+}
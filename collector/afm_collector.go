@@ -0,0 +1,202 @@
+package collector
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// An AFMCollector implements the prometheus.Collector.
+type AFMCollector struct {
+	ruleHitsDesc            *prometheus.Desc
+	ruleLastHitDesc         *prometheus.Desc
+	deviceVectorDesc        *prometheus.Desc
+	profileVectorDesc       *prometheus.Desc
+	client                  *Client
+	partitionsList          []string
+	collectorScrapeStatus   *prometheus.GaugeVec
+	collectorScrapeDuration *prometheus.SummaryVec
+}
+
+// dosVectorCounts maps the kind label of the DoS vector metrics to the stat
+// holding its count.
+var dosVectorCounts = map[string]string{
+	"detected":  "detectedCount",
+	"dropped":   "droppedCount",
+	"mitigated": "mitigatedCount",
+}
+
+type firewallPoliciesResponse struct {
+	Items []struct {
+		Name      string `json:"name"`
+		Partition string `json:"partition"`
+		FullPath  string `json:"fullPath"`
+	} `json:"items"`
+}
+
+// NewAFMCollector returns a collector that collecting firewall rule and DoS vector statistics
+func NewAFMCollector(client *Client, namespace string, partitionsList []string) (*AFMCollector, error) {
+	var (
+		subsystem      = "afm"
+		ruleLabelNames = []string{"partition", "policy", "rule_list", "rule", "context_type", "context"}
+	)
+	return &AFMCollector{
+		ruleHitsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "rule_hits_total"),
+			"rule_hits_total",
+			ruleLabelNames,
+			nil,
+		),
+		ruleLastHitDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "rule_last_hit_timestamp_seconds"),
+			"rule_last_hit_timestamp_seconds",
+			ruleLabelNames,
+			nil,
+		),
+		deviceVectorDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "dos_device_vector_total"),
+			"dos_device_vector_total",
+			[]string{"vector", "kind"},
+			nil,
+		),
+		profileVectorDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "dos_profile_vector_total"),
+			"dos_profile_vector_total",
+			[]string{"partition", "profile", "vector", "kind"},
+			nil,
+		),
+		collectorScrapeStatus: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_status",
+				Help:      "collector_scrape_status",
+			},
+			[]string{"collector"},
+		),
+		collectorScrapeDuration: prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_duration",
+				Help:      "collector_scrape_duration",
+			},
+			[]string{"collector"},
+		),
+		client:         client,
+		partitionsList: partitionsList,
+	}, nil
+}
+
+// Collect collects metrics for BIG-IP AFM firewall rules and DoS vectors.
+// Nothing is collected when AFM is not provisioned.
+func (c *AFMCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	provisioned, err := c.client.provisioned("afm")
+	if err == nil && provisioned {
+		if err = c.collectFirewallRules(ch); err != nil {
+			logger.Warningf("Failed to get statistics for firewall rules (%s)", err)
+		}
+		if dosErr := c.collectDoSVectors(ch); dosErr != nil {
+			logger.Warningf("Failed to get statistics for DoS vectors (%s)", dosErr)
+			err = dosErr
+		}
+	} else if err != nil {
+		logger.Warningf("Failed to get provisioned modules (%s)", err)
+	}
+	if err != nil {
+		c.collectorScrapeStatus.WithLabelValues("afm").Set(float64(0))
+	} else {
+		c.collectorScrapeStatus.WithLabelValues("afm").Set(float64(1))
+		logger.Debugf("Successfully fetched statistics for AFM")
+	}
+
+	elapsed := time.Since(start)
+	c.collectorScrapeDuration.WithLabelValues("afm").Observe(float64(elapsed.Seconds()))
+	c.collectorScrapeStatus.Collect(ch)
+	c.collectorScrapeDuration.Collect(ch)
+	logger.Debugf("Getting AFM statistics took %s", elapsed)
+}
+
+// collectFirewallRules exports the hit count and last hit time of each rule.
+// Rule statistics are only available per policy. A rule name is only unique
+// within its rule list, and a policy is counted separately in each context
+// it is enforced in, e.g. globally or on a virtual server, so both are part
+// of the labels.
+func (c *AFMCollector) collectFirewallRules(ch chan<- prometheus.Metric) error {
+	var policies firewallPoliciesResponse
+	if err := c.client.get("/mgmt/tm/security/firewall/policy", &policies); err != nil {
+		return err
+	}
+	for _, policy := range policies.Items {
+		if c.partitionsList != nil && !stringInSlice(policy.Partition, c.partitionsList) {
+			continue
+		}
+		var ruleStats statsResponse
+		path := "/mgmt/tm/security/firewall/policy/" + strings.Replace(policy.FullPath, "/", "~", -1) + "/stats"
+		if err := c.client.get(path, &ruleStats); err != nil {
+			return err
+		}
+		for _, rule := range ruleStats.Entries {
+			entries := rule.NestedStats.Entries
+			labels := []string{
+				policy.Partition,
+				policy.Name,
+				entries.description("ruleListName"),
+				entries.description("ruleName"),
+				entries.description("contextType"),
+				entries.description("contextName"),
+			}
+			ch <- prometheus.MustNewConstMetric(c.ruleHitsDesc, prometheus.CounterValue, entries.value("counter"), labels...)
+			if lastHit := entries.value("lastHitTime"); lastHit > 0 {
+				ch <- prometheus.MustNewConstMetric(c.ruleLastHitDesc, prometheus.GaugeValue, lastHit, labels...)
+			}
+		}
+	}
+	return nil
+}
+
+// collectDoSVectors exports the detected, dropped and mitigated counts of
+// the device wide DoS vectors and of the vectors of each DoS profile.
+func (c *AFMCollector) collectDoSVectors(ch chan<- prometheus.Metric) error {
+	// Device vectors are shared by all partitions.
+	if c.partitionsList == nil {
+		var deviceStats statsResponse
+		if err := c.client.get("/mgmt/tm/security/dos/device-config/stats", &deviceStats); err != nil {
+			return err
+		}
+		for _, vector := range deviceStats.Entries {
+			entries := vector.NestedStats.Entries
+			for kind, stat := range dosVectorCounts {
+				ch <- prometheus.MustNewConstMetric(c.deviceVectorDesc, prometheus.CounterValue, entries.value(stat), entries.description("vector"), kind)
+			}
+		}
+	}
+
+	var profileStats statsResponse
+	if err := c.client.get("/mgmt/tm/security/dos/profile/stats", &profileStats); err != nil {
+		return err
+	}
+	for _, vector := range profileStats.Entries {
+		entries := vector.NestedStats.Entries
+		partition, profileName := splitFullPath(entries.description("tmName"))
+
+		if c.partitionsList != nil && !stringInSlice(partition, c.partitionsList) {
+			continue
+		}
+
+		for kind, stat := range dosVectorCounts {
+			ch <- prometheus.MustNewConstMetric(c.profileVectorDesc, prometheus.CounterValue, entries.value(stat), partition, profileName, entries.description("vector"), kind)
+		}
+	}
+	return nil
+}
+
+// Describe describes the metrics exported from this collector.
+func (c *AFMCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.ruleHitsDesc
+	ch <- c.ruleLastHitDesc
+	ch <- c.deviceVectorDesc
+	ch <- c.profileVectorDesc
+	c.collectorScrapeStatus.Describe(ch)
+	c.collectorScrapeDuration.Describe(ch)
+}
@@ -0,0 +1,185 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// An APMCollector implements the prometheus.Collector.
+type APMCollector struct {
+	metrics                 map[string]statsMetric
+	resultsDesc             *prometheus.Desc
+	licensedSessionsDesc    *prometheus.Desc
+	licensedLimitDesc       *prometheus.Desc
+	client                  *Client
+	partitionsList          []string
+	collectorScrapeStatus   *prometheus.GaugeVec
+	collectorScrapeDuration *prometheus.SummaryVec
+}
+
+// apmResults maps the result label of the access policy results metric to
+// the stat holding its count.
+var apmResults = map[string]string{
+	"allow": "accessPolicyResult.allow",
+	"deny":  "accessPolicyResult.deny",
+	"error": "accessPolicyResult.error",
+}
+
+// NewAPMCollector returns a collector that collecting access profile statistics
+func NewAPMCollector(client *Client, namespace string, partitionsList []string) (*APMCollector, error) {
+	var (
+		subsystem  = "apm"
+		labelNames = []string{"partition", "profile"}
+	)
+	return &APMCollector{
+		metrics: map[string]statsMetric{
+			"currentActiveSessions": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "active_sessions"),
+					"active_sessions",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("currentActiveSessions")
+				},
+				valueType: prometheus.GaugeValue,
+			},
+			"currentPendingSessions": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "pending_sessions"),
+					"pending_sessions",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("currentPendingSessions")
+				},
+				valueType: prometheus.GaugeValue,
+			},
+			"totalSessions": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "sessions_total"),
+					"sessions_total",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("totalSessions")
+				},
+				valueType: prometheus.CounterValue,
+			},
+		},
+		resultsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "access_policy_results_total"),
+			"access_policy_results_total",
+			[]string{"partition", "profile", "result"},
+			nil,
+		),
+		licensedSessionsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "license_access_sessions"),
+			"license_access_sessions",
+			nil,
+			nil,
+		),
+		licensedLimitDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "license_access_sessions_limit"),
+			"license_access_sessions_limit",
+			nil,
+			nil,
+		),
+		collectorScrapeStatus: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_status",
+				Help:      "collector_scrape_status",
+			},
+			[]string{"collector"},
+		),
+		collectorScrapeDuration: prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_duration",
+				Help:      "collector_scrape_duration",
+			},
+			[]string{"collector"},
+		),
+		client:         client,
+		partitionsList: partitionsList,
+	}, nil
+}
+
+// Collect collects metrics for BIG-IP access profiles. Nothing is collected
+// when APM is not provisioned.
+func (c *APMCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	provisioned, err := c.client.provisioned("apm")
+	if err == nil && provisioned {
+		err = c.collect(ch)
+	}
+	if err != nil {
+		c.collectorScrapeStatus.WithLabelValues("apm").Set(float64(0))
+		logger.Warningf("Failed to get statistics for access profiles (%s)", err)
+	} else {
+		c.collectorScrapeStatus.WithLabelValues("apm").Set(float64(1))
+		logger.Debugf("Successfully fetched statistics for access profiles")
+	}
+
+	elapsed := time.Since(start)
+	c.collectorScrapeDuration.WithLabelValues("apm").Observe(float64(elapsed.Seconds()))
+	c.collectorScrapeStatus.Collect(ch)
+	c.collectorScrapeDuration.Collect(ch)
+	logger.Debugf("Getting access profile statistics took %s", elapsed)
+}
+
+func (c *APMCollector) collect(ch chan<- prometheus.Metric) error {
+	var allProfileStats statsResponse
+	if err := c.client.get("/mgmt/tm/apm/profile/access/stats", &allProfileStats); err != nil {
+		return err
+	}
+	for _, profileStats := range allProfileStats.Entries {
+		entries := profileStats.NestedStats.Entries
+		partition, profileName := splitFullPath(entries.description("tmName"))
+
+		if c.partitionsList != nil && !stringInSlice(partition, c.partitionsList) {
+			continue
+		}
+
+		labels := []string{partition, profileName}
+		for _, metric := range c.metrics {
+			ch <- prometheus.MustNewConstMetric(metric.desc, metric.valueType, metric.extract(entries), labels...)
+		}
+		for result, stat := range apmResults {
+			ch <- prometheus.MustNewConstMetric(c.resultsDesc, prometheus.CounterValue, entries.value(stat), partition, profileName, result)
+		}
+	}
+
+	// Licensed sessions are shared by all partitions.
+	if c.partitionsList != nil {
+		return nil
+	}
+	var license statsResponse
+	if err := c.client.get("/mgmt/tm/apm/license", &license); err != nil {
+		return err
+	}
+	for _, licenseStats := range license.Entries {
+		entries := licenseStats.NestedStats.Entries
+		ch <- prometheus.MustNewConstMetric(c.licensedSessionsDesc, prometheus.GaugeValue, entries.value("accessSessionsCurrent"))
+		ch <- prometheus.MustNewConstMetric(c.licensedLimitDesc, prometheus.GaugeValue, entries.value("accessSessionsMaxAllowed"))
+		break
+	}
+	return nil
+}
+
+// Describe describes the metrics exported from this collector.
+func (c *APMCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, metric := range c.metrics {
+		ch <- metric.desc
+	}
+	ch <- c.resultsDesc
+	ch <- c.licensedSessionsDesc
+	ch <- c.licensedLimitDesc
+	c.collectorScrapeStatus.Describe(ch)
+	c.collectorScrapeDuration.Describe(ch)
+}
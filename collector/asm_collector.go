@@ -0,0 +1,177 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// An ASMCollector implements the prometheus.Collector.
+type ASMCollector struct {
+	policyInfoDesc          *prometheus.Desc
+	policyActiveDesc        *prometheus.Desc
+	policyBlockingDesc      *prometheus.Desc
+	policyAppliedDesc       *prometheus.Desc
+	policyVSDesc            *prometheus.Desc
+	signatureUpdateDesc     *prometheus.Desc
+	client                  *Client
+	partitionsList          []string
+	collectorScrapeStatus   *prometheus.GaugeVec
+	collectorScrapeDuration *prometheus.SummaryVec
+}
+
+type asmPoliciesResponse struct {
+	Items []struct {
+		Name            string   `json:"name"`
+		Partition       string   `json:"partition"`
+		FullPath        string   `json:"fullPath"`
+		EnforcementMode string   `json:"enforcementMode"`
+		Active          bool     `json:"active"`
+		VersionDatetime string   `json:"versionDatetime"`
+		VirtualServers  []string `json:"virtualServers"`
+	} `json:"items"`
+}
+
+type asmSignatureStatusesResponse struct {
+	Items []struct {
+		Timestamp     string `json:"timestamp"`
+		IsUserDefined bool   `json:"isUserDefined"`
+	} `json:"items"`
+}
+
+// NewASMCollector returns a collector that collecting ASM security policies
+func NewASMCollector(client *Client, namespace string, partitionsList []string) (*ASMCollector, error) {
+	subsystem := "asm"
+	return &ASMCollector{
+		policyInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "policy_info"),
+			"policy_info",
+			[]string{"partition", "policy", "enforcement_mode"},
+			nil,
+		),
+		policyActiveDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "policy_active"),
+			"policy_active",
+			[]string{"partition", "policy"},
+			nil,
+		),
+		policyBlockingDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "policy_blocking"),
+			"policy_blocking",
+			[]string{"partition", "policy"},
+			nil,
+		),
+		policyAppliedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "policy_last_applied_timestamp_seconds"),
+			"policy_last_applied_timestamp_seconds",
+			[]string{"partition", "policy"},
+			nil,
+		),
+		policyVSDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "policy_vs_info"),
+			"policy_vs_info",
+			[]string{"partition", "policy", "vs_partition", "vs"},
+			nil,
+		),
+		signatureUpdateDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "signature_update_timestamp_seconds"),
+			"signature_update_timestamp_seconds",
+			nil,
+			nil,
+		),
+		collectorScrapeStatus: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_status",
+				Help:      "collector_scrape_status",
+			},
+			[]string{"collector"},
+		),
+		collectorScrapeDuration: prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_duration",
+				Help:      "collector_scrape_duration",
+			},
+			[]string{"collector"},
+		),
+		client:         client,
+		partitionsList: partitionsList,
+	}, nil
+}
+
+// Collect collects metrics for BIG-IP ASM security policies. Nothing is
+// collected when ASM is not provisioned.
+func (c *ASMCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	provisioned, err := c.client.provisioned("asm")
+	if err == nil && provisioned {
+		err = c.collect(ch)
+	}
+	if err != nil {
+		c.collectorScrapeStatus.WithLabelValues("asm").Set(float64(0))
+		logger.Warningf("Failed to get ASM policies (%s)", err)
+	} else {
+		c.collectorScrapeStatus.WithLabelValues("asm").Set(float64(1))
+		logger.Debugf("Successfully fetched ASM policies")
+	}
+
+	elapsed := time.Since(start)
+	c.collectorScrapeDuration.WithLabelValues("asm").Observe(float64(elapsed.Seconds()))
+	c.collectorScrapeStatus.Collect(ch)
+	c.collectorScrapeDuration.Collect(ch)
+	logger.Debugf("Getting ASM policies took %s", elapsed)
+}
+
+func (c *ASMCollector) collect(ch chan<- prometheus.Metric) error {
+	var policies asmPoliciesResponse
+	if err := c.client.get("/mgmt/tm/asm/policies", &policies); err != nil {
+		return err
+	}
+	for _, policy := range policies.Items {
+		if c.partitionsList != nil && !stringInSlice(policy.Partition, c.partitionsList) {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.policyInfoDesc, prometheus.GaugeValue, 1, policy.Partition, policy.Name, policy.EnforcementMode)
+		ch <- prometheus.MustNewConstMetric(c.policyActiveDesc, prometheus.GaugeValue, boolToFloat64(policy.Active), policy.Partition, policy.Name)
+		ch <- prometheus.MustNewConstMetric(c.policyBlockingDesc, prometheus.GaugeValue, boolToFloat64(policy.Active && policy.EnforcementMode == "blocking"), policy.Partition, policy.Name)
+		if applied, ok := parseTimestamp(policy.VersionDatetime); ok {
+			ch <- prometheus.MustNewConstMetric(c.policyAppliedDesc, prometheus.GaugeValue, applied, policy.Partition, policy.Name)
+		}
+		for _, vs := range policy.VirtualServers {
+			vsPartition, vsName := splitFullPath(vs)
+			ch <- prometheus.MustNewConstMetric(c.policyVSDesc, prometheus.GaugeValue, 1, policy.Partition, policy.Name, vsPartition, vsName)
+		}
+	}
+
+	// The signature files shipped by F5 carry the time of the last update.
+	var statuses asmSignatureStatusesResponse
+	if err := c.client.get("/mgmt/tm/asm/signature-statuses", &statuses); err != nil {
+		return err
+	}
+	var latest float64
+	for _, status := range statuses.Items {
+		if status.IsUserDefined {
+			continue
+		}
+		if updated, ok := parseTimestamp(status.Timestamp); ok && updated > latest {
+			latest = updated
+		}
+	}
+	if latest > 0 {
+		ch <- prometheus.MustNewConstMetric(c.signatureUpdateDesc, prometheus.GaugeValue, latest)
+	}
+	return nil
+}
+
+// Describe describes the metrics exported from this collector.
+func (c *ASMCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.policyInfoDesc
+	ch <- c.policyActiveDesc
+	ch <- c.policyBlockingDesc
+	ch <- c.policyAppliedDesc
+	ch <- c.policyVSDesc
+	ch <- c.signatureUpdateDesc
+	c.collectorScrapeStatus.Describe(ch)
+	c.collectorScrapeDuration.Describe(ch)
+}
@@ -19,19 +19,69 @@ var (
 	logger = loggo.GetLogger("")
 )
 
+// Options configures optional collectors.
+type Options struct {
+	// Persistence enables the PersistenceCollector, which reads the
+	// persistence records and the connection table.
+	Persistence bool
+	// PersistenceMaxRows is the maximum number of rows the
+	// PersistenceCollector reads from each table.
+	PersistenceMaxRows int
+}
+
 // NewBigipCollector returns a collector that wraps all the collectors
-func NewBigipCollector(bigip *f5.Device, namespace string, partitionsList []string) (*BigipCollector, error) {
+func NewBigipCollector(bigip *f5.Device, client *Client, namespace string, partitionsList []string, options Options) (*BigipCollector, error) {
 	vsCollector, _ := NewVSCollector(bigip, namespace, partitionsList)
 	poolCollector, _ := NewPoolCollector(bigip, namespace, partitionsList)
 	nodeCollector, _ := NewNodeCollector(bigip, namespace, partitionsList)
-	ruleCollector, _ := NewRuleCollector(bigip, namespace, partitionsList)
+	ruleCollector, _ := NewRuleCollector(bigip, client, namespace, partitionsList)
+	accelerationProfileCollector, _ := NewAccelerationProfileCollector(client, namespace, partitionsList)
+	afmCollector, _ := NewAFMCollector(client, namespace, partitionsList)
+	apmCollector, _ := NewAPMCollector(client, namespace, partitionsList)
+	asmCollector, _ := NewASMCollector(client, namespace, partitionsList)
+	cmCollector, _ := NewCMCollector(client, namespace)
+	dataGroupCollector, _ := NewDataGroupCollector(client, namespace, partitionsList)
+	hardwareCollector, _ := NewHardwareCollector(client, namespace)
+	httpProfileCollector, _ := NewHTTPProfileCollector(client, namespace, partitionsList)
+	licenseCollector, _ := NewLicenseCollector(client, namespace)
+	ltmPolicyCollector, _ := NewLTMPolicyCollector(client, namespace, partitionsList)
+	monitorCollector, _ := NewMonitorCollector(client, namespace, partitionsList)
+	netCollector, _ := NewNetCollector(client, namespace, partitionsList)
+	snatCollector, _ := NewSNATCollector(client, namespace, partitionsList)
+	softwareCollector, _ := NewSoftwareCollector(client, namespace)
+	sslCollector, _ := NewSSLCollector(client, namespace, partitionsList)
+	tcpProfileCollector, _ := NewTCPProfileCollector(client, namespace, partitionsList)
+	trafficCollector, _ := NewTrafficCollector(client, namespace)
+	vcmpCollector, _ := NewVCMPCollector(client, namespace)
+	collectors := map[string]prometheus.Collector{
+		"acceleration_profile": accelerationProfileCollector,
+		"afm":                  afmCollector,
+		"apm":                  apmCollector,
+		"asm":                  asmCollector,
+		"cm":                   cmCollector,
+		"data_group":           dataGroupCollector,
+		"hardware":             hardwareCollector,
+		"http_profile":         httpProfileCollector,
+		"license":              licenseCollector,
+		"ltm_policy":           ltmPolicyCollector,
+		"monitor":              monitorCollector,
+		"net":                  netCollector,
+		"node":                 nodeCollector,
+		"pool":                 poolCollector,
+		"rule":                 ruleCollector,
+		"snat":                 snatCollector,
+		"software":             softwareCollector,
+		"ssl":                  sslCollector,
+		"tcp_profile":          tcpProfileCollector,
+		"traffic":              trafficCollector,
+		"vcmp":                 vcmpCollector,
+		"vs":                   vsCollector,
+	}
+	if options.Persistence {
+		collectors["persistence"], _ = NewPersistenceCollector(client, namespace, partitionsList, options.PersistenceMaxRows)
+	}
 	return &BigipCollector{
-		collectors: map[string]prometheus.Collector{
-			"node": nodeCollector,
-			"pool": poolCollector,
-			"rule": ruleCollector,
-			"vs":   vsCollector,
-		},
+		collectors: collectors,
 		totalScrapeDuration: prometheus.NewSummary(
 			prometheus.SummaryOpts{
 				Namespace: namespace,
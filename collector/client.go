@@ -0,0 +1,370 @@
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultLoginProvider is the login provider of local BIG-IP users.
+const DefaultLoginProvider = "tmos"
+
+// A Client talks to the iControl REST API of a BIG-IP. It is used for the
+// parts of the API that the f5 package does not cover.
+type Client struct {
+	host          string
+	user          string
+	password      string
+	basicAuth     bool
+	loginProvider string
+	httpClient    *http.Client
+
+	mu       sync.Mutex
+	token    string
+	loginErr error
+
+	provisionMu sync.Mutex
+	provision   map[string]string
+
+	hardwareMu      sync.Mutex
+	hardwareFetched bool
+	hardwareStats   statsResponse
+	hardwareErr     error
+
+	virtualServersMu      sync.Mutex
+	virtualServersFetched bool
+	virtualServersItems   []virtualServer
+	virtualServersErr     error
+}
+
+// A LoginError is returned when the BIG-IP rejects a token request.
+type LoginError struct {
+	StatusCode    int
+	LoginProvider string
+	Message       string
+}
+
+func (e *LoginError) Error() string {
+	return fmt.Sprintf("login with provider %s failed with status %d: %s", e.LoginProvider, e.StatusCode, e.Message)
+}
+
+// NewClient returns a client for the BIG-IP at host. Unless basicAuth is
+// set, requests are authenticated with a token obtained from loginProvider,
+// or from the local tmos provider if loginProvider is empty.
+func NewClient(host, user, password string, basicAuth bool, loginProvider string) *Client {
+	if loginProvider == "" {
+		loginProvider = DefaultLoginProvider
+	}
+	return &Client{
+		host:          host,
+		user:          user,
+		password:      password,
+		basicAuth:     basicAuth,
+		loginProvider: loginProvider,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+		},
+	}
+}
+
+// Token returns an authentication token, logging in first if the client
+// does not hold one yet. A failed login is remembered and returned to all
+// later callers, so the collectors of a scrape do not each retry it and
+// lock out the account.
+func (c *Client) Token() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" {
+		return c.token, nil
+	}
+	if c.loginErr != nil {
+		return "", c.loginErr
+	}
+	token, err := c.login()
+	if err != nil {
+		c.loginErr = err
+		return "", err
+	}
+	c.token = token
+	return token, nil
+}
+
+func (c *Client) login() (string, error) {
+	body, err := json.Marshal(map[string]string{
+		"username":          c.user,
+		"password":          c.password,
+		"loginProviderName": c.loginProvider,
+	})
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.httpClient.Post("https://"+c.host+"/mgmt/shared/authn/login", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Message string `json:"message"`
+		Token   struct {
+			Token string `json:"token"`
+		} `json:"token"`
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	// Error responses are usually json too, but fall back to the raw body.
+	if err := json.Unmarshal(data, &result); err != nil && resp.StatusCode == http.StatusOK {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK || result.Token.Token == "" {
+		message := result.Message
+		if message == "" {
+			message = string(data)
+		}
+		return "", &LoginError{StatusCode: resp.StatusCode, LoginProvider: c.loginProvider, Message: message}
+	}
+	return result.Token.Token, nil
+}
+
+// provisioned reports whether module, e.g. asm, is provisioned. The
+// provisioning of all modules is read once and shared by the collectors.
+func (c *Client) provisioned(module string) (bool, error) {
+	c.provisionMu.Lock()
+	defer c.provisionMu.Unlock()
+	if c.provision == nil {
+		var resp struct {
+			Items []struct {
+				Name  string `json:"name"`
+				Level string `json:"level"`
+			} `json:"items"`
+		}
+		if err := c.get("/mgmt/tm/sys/provision", &resp); err != nil {
+			return false, err
+		}
+		c.provision = make(map[string]string)
+		for _, item := range resp.Items {
+			c.provision[item.Name] = item.Level
+		}
+	}
+	level := c.provision[module]
+	return level != "" && level != "none", nil
+}
+
+// hardware returns /mgmt/tm/sys/hardware. It is read once and shared by the
+// collectors.
+func (c *Client) hardware() (statsResponse, error) {
+	c.hardwareMu.Lock()
+	defer c.hardwareMu.Unlock()
+	if !c.hardwareFetched {
+		c.hardwareErr = c.get("/mgmt/tm/sys/hardware", &c.hardwareStats)
+		c.hardwareFetched = true
+	}
+	return c.hardwareStats, c.hardwareErr
+}
+
+// get fetches path from the iControl REST API and decodes the json response
+// into v.
+func (c *Client) get(path string, v interface{}) error {
+	body, err := c.open(path)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return json.NewDecoder(body).Decode(v)
+}
+
+// getStatsEntries streams the entries of a stats endpoint to fn without
+// decoding the whole response, and stops reading after max entries, unless
+// max is not positive. It reports whether entries were left unread.
+func (c *Client) getStatsEntries(path string, max int, fn func(statsEntries)) (bool, error) {
+	body, err := c.open(path)
+	if err != nil {
+		return false, err
+	}
+	defer body.Close()
+
+	dec := json.NewDecoder(body)
+	if _, err := dec.Token(); err != nil {
+		return false, err
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return false, err
+		}
+		if key != "entries" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return false, err
+			}
+			continue
+		}
+		if _, err := dec.Token(); err != nil {
+			return false, err
+		}
+		for n := 0; dec.More(); n++ {
+			if max > 0 && n == max {
+				return true, nil
+			}
+			if _, err := dec.Token(); err != nil {
+				return false, err
+			}
+			var entry statsEntry
+			if err := dec.Decode(&entry); err != nil {
+				return false, err
+			}
+			fn(entry.NestedStats.Entries)
+		}
+		return false, nil
+	}
+	return false, nil
+}
+
+// getRawLines streams the lines of the raw tmsh output that some endpoints
+// return in apiRawValues.apiAnonymous to fn, without reading the whole
+// response. It stops reading as soon as fn returns false.
+func (c *Client) getRawLines(path string, fn func(string) bool) error {
+	body, err := c.open(path)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	r := bufio.NewReader(body)
+	if err := skipPast(r, `"apiAnonymous"`); err != nil {
+		return err
+	}
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if b == '"' {
+			break
+		}
+	}
+
+	// The output is a single json string, so decode its escapes while
+	// splitting it into lines.
+	var line bytes.Buffer
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		switch b {
+		case '"':
+			if line.Len() > 0 {
+				fn(line.String())
+			}
+			return nil
+		case '\\':
+			e, err := r.ReadByte()
+			if err != nil {
+				return err
+			}
+			switch e {
+			case 'n':
+				if !fn(line.String()) {
+					return nil
+				}
+				line.Reset()
+			case 't':
+				line.WriteByte('\t')
+			case 'r':
+				line.WriteByte('\r')
+			case 'b':
+				line.WriteByte('\b')
+			case 'f':
+				line.WriteByte('\f')
+			case 'u':
+				var hex [4]byte
+				if _, err := io.ReadFull(r, hex[:]); err != nil {
+					return err
+				}
+				code, err := strconv.ParseUint(string(hex[:]), 16, 16)
+				if err != nil {
+					return err
+				}
+				line.WriteRune(rune(code))
+			default:
+				line.WriteByte(e)
+			}
+		default:
+			line.WriteByte(b)
+		}
+	}
+}
+
+// skipPast reads r up to and including the first occurrence of s.
+func skipPast(r *bufio.Reader, s string) error {
+	window := make([]byte, 0, len(s))
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if len(window) == len(s) {
+			window = append(window[:0], window[1:]...)
+		}
+		window = append(window, b)
+		if string(window) == s {
+			return nil
+		}
+	}
+}
+
+// open requests path from the iControl REST API and returns the body of a
+// successful response. An expired token is renewed once.
+func (c *Client) open(path string) (io.ReadCloser, error) {
+	resp, err := c.do(path)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && !c.basicAuth {
+		resp.Body.Close()
+		c.mu.Lock()
+		c.token = ""
+		c.mu.Unlock()
+		if resp, err = c.do(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		data, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("GET %s returned status %d: %s", path, resp.StatusCode, bytes.TrimSpace(data))
+	}
+	return resp.Body, nil
+}
+
+func (c *Client) do(path string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", "https://"+c.host+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.basicAuth {
+		req.SetBasicAuth(c.user, c.password)
+	} else {
+		token, err := c.Token()
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("X-F5-Auth-Token", token)
+	}
+	return c.httpClient.Do(req)
+}
@@ -0,0 +1,176 @@
+package collector
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newTestClient returns a client for a TLS server that answers every
+// request with body.
+func newTestClient(t *testing.T, body string) (*Client, func()) {
+	t.Helper()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	return NewClient(strings.TrimPrefix(server.URL, "https://"), "admin", "secret", true, ""), server.Close
+}
+
+// newTestClientForPaths returns a client for a TLS server that answers
+// requests with the body of their path, and with 404 for other paths.
+func newTestClientForPaths(t *testing.T, bodies map[string]string) (*Client, func()) {
+	t.Helper()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := bodies[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	return NewClient(strings.TrimPrefix(server.URL, "https://"), "admin", "secret", true, ""), server.Close
+}
+
+func TestGetRawLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		stop  int
+		lines []string
+	}{
+		{
+			name:  "lines",
+			body:  `{"kind":"tm:sys:connection:connectionstats","apiRawValues":{"apiAnonymous":"a b\nc d\n"}}`,
+			lines: []string{"a b", "c d"},
+		},
+		{
+			name:  "last line without newline",
+			body:  `{"apiRawValues":{"apiAnonymous":"a\nb"}}`,
+			lines: []string{"a", "b"},
+		},
+		{
+			name:  "escapes",
+			body:  `{"apiRawValues":{"apiAnonymous":"say \"hi\"\nC:\\tmp\\n\nx\ty\u00e9\u0041\n"}}`,
+			lines: []string{`say "hi"`, `C:\tmp\n`, "x\tyéA"},
+		},
+		{
+			name:  "total records",
+			body:  `{"apiRawValues":{"apiAnonymous":"10.0.0.1:51234  10.0.0.2:80  10.0.0.1:51234  10.1.1.1:80  tcp  3  (tmm: 1)  none\nTotal records returned: 1\n"}}`,
+			lines: []string{"10.0.0.1:51234  10.0.0.2:80  10.0.0.1:51234  10.1.1.1:80  tcp  3  (tmm: 1)  none", "Total records returned: 1"},
+		},
+		{
+			name:  "escaped apiAnonymous in another string",
+			body:  `{"command":"\"apiAnonymous\" is not here","apiRawValues":{"apiAnonymous":"a\n"}}`,
+			lines: []string{"a"},
+		},
+		{
+			name:  "stop",
+			body:  `{"apiRawValues":{"apiAnonymous":"a\nb\nc\n"}}`,
+			stop:  2,
+			lines: []string{"a", "b"},
+		},
+	}
+	for _, test := range tests {
+		client, done := newTestClient(t, test.body)
+		var lines []string
+		err := client.getRawLines("/mgmt/tm/sys/connection", func(line string) bool {
+			lines = append(lines, line)
+			return len(lines) != test.stop
+		})
+		done()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(lines, test.lines) {
+			t.Errorf("%s: got lines %q, want %q", test.name, lines, test.lines)
+		}
+	}
+}
+
+func TestGetRawLinesWithoutOutput(t *testing.T) {
+	client, done := newTestClient(t, `{"kind":"tm:sys:connection:connectionstats"}`)
+	defer done()
+	if err := client.getRawLines("/mgmt/tm/sys/connection", func(string) bool { return true }); err == nil {
+		t.Error("expected an error for a response without apiAnonymous")
+	}
+}
+
+func TestSkipPast(t *testing.T) {
+	tests := []struct {
+		input  string
+		needle string
+		rest   string
+		found  bool
+	}{
+		{`{"apiAnonymous":"x"}`, `"apiAnonymous"`, `:"x"}`, true},
+		{`""apiAnonymous":"x"}`, `"apiAnonymous"`, `:"x"}`, true},
+		{`"apiAnon"apiAnonymous":"x"}`, `"apiAnonymous"`, `:"x"}`, true},
+		{`aaab`, `aab`, ``, true},
+		{`abababc!`, `ababc`, `!`, true},
+		{`{"apiAnonymou"}`, `"apiAnonymous"`, ``, false},
+	}
+	for _, test := range tests {
+		r := bufio.NewReader(strings.NewReader(test.input))
+		err := skipPast(r, test.needle)
+		if found := err == nil; found != test.found {
+			t.Errorf("%q in %q: got found %t, want %t", test.needle, test.input, found, test.found)
+			continue
+		}
+		if !test.found {
+			continue
+		}
+		rest, _ := r.ReadString(0)
+		if rest != test.rest {
+			t.Errorf("%q in %q: got rest %q, want %q", test.needle, test.input, rest, test.rest)
+		}
+	}
+}
+
+func TestGetStatsEntries(t *testing.T) {
+	body := `{"kind":"tm:ltm:persistence:persist-records:persist-recordsstats",` +
+		`"selfLink":"https://localhost/mgmt/tm/ltm/persistence/persist-records",` +
+		`"entries":{` +
+		`"https://localhost/mgmt/tm/ltm/persistence/persist-records/0":{"nestedStats":{"entries":{"mode":{"description":"source-address"},"nodePort":{"value":80}}}},` +
+		`"https://localhost/mgmt/tm/ltm/persistence/persist-records/1":{"nestedStats":{"entries":{"mode":{"description":"cookie"},"nodePort":{"value":443}}}},` +
+		`"https://localhost/mgmt/tm/ltm/persistence/persist-records/2":{"nestedStats":{"entries":{"mode":{"description":"ssl"},"nodePort":{"value":8443}}}}` +
+		`}}`
+
+	tests := []struct {
+		name      string
+		body      string
+		max       int
+		modes     []string
+		truncated bool
+	}{
+		{name: "all", body: body, max: 10, modes: []string{"source-address", "cookie", "ssl"}},
+		{name: "exactly max", body: body, max: 3, modes: []string{"source-address", "cookie", "ssl"}},
+		{name: "cut off", body: body, max: 2, modes: []string{"source-address", "cookie"}, truncated: true},
+		{name: "zero max", body: body, max: 0, modes: []string{"source-address", "cookie", "ssl"}},
+		{name: "negative max", body: body, max: -1, modes: []string{"source-address", "cookie", "ssl"}},
+		{name: "no entries", body: `{"kind":"tm:ltm:persistence:persist-records:persist-recordsstats"}`, max: 10},
+	}
+	for _, test := range tests {
+		client, done := newTestClient(t, test.body)
+		var modes []string
+		truncated, err := client.getStatsEntries("/mgmt/tm/ltm/persistence/persist-records", test.max, func(entries statsEntries) {
+			modes = append(modes, entries.description("mode"))
+		})
+		done()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(modes, test.modes) {
+			t.Errorf("%s: got modes %q, want %q", test.name, modes, test.modes)
+		}
+		if truncated != test.truncated {
+			t.Errorf("%s: got truncated %t, want %t", test.name, truncated, test.truncated)
+		}
+	}
+}
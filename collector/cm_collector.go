@@ -0,0 +1,233 @@
+package collector
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// A CMCollector implements the prometheus.Collector.
+type CMCollector struct {
+	trafficGroupInfoDesc    *prometheus.Desc
+	trafficGroupActiveDesc  *prometheus.Desc
+	trafficGroupNextDesc    *prometheus.Desc
+	deviceInfoDesc          *prometheus.Desc
+	deviceTrustedDesc       *prometheus.Desc
+	deviceConnectedDesc     *prometheus.Desc
+	client                  *Client
+	collectorScrapeStatus   *prometheus.GaugeVec
+	collectorScrapeDuration *prometheus.SummaryVec
+}
+
+type trafficGroupsResponse struct {
+	Items []struct {
+		Name                string   `json:"name"`
+		FullPath            string   `json:"fullPath"`
+		FailoverMethod      string   `json:"failoverMethod"`
+		HAOrder             []string `json:"haOrder"`
+		AutoFailbackEnabled string   `json:"autoFailbackEnabled"`
+	} `json:"items"`
+}
+
+type devicesResponse struct {
+	Items []struct {
+		Name          string `json:"name"`
+		Hostname      string `json:"hostname"`
+		ManagementIP  string `json:"managementIp"`
+		Version       string `json:"version"`
+		Build         string `json:"build"`
+		FailoverState string `json:"failoverState"`
+		SelfDevice    string `json:"selfDevice"`
+	} `json:"items"`
+}
+
+type trustDomainsResponse struct {
+	Items []struct {
+		CADevices    []string `json:"caDevices"`
+		NonCADevices []string `json:"nonCaDevices"`
+	} `json:"items"`
+}
+
+// NewCMCollector returns a collector that collecting traffic groups and the devices of the trust domain
+func NewCMCollector(client *Client, namespace string) (*CMCollector, error) {
+	subsystem := "cm"
+	return &CMCollector{
+		trafficGroupInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "traffic_group_info"),
+			"traffic_group_info",
+			[]string{"traffic_group", "failover_method", "ha_order", "auto_failback"},
+			nil,
+		),
+		trafficGroupActiveDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "traffic_group_active"),
+			"traffic_group_active",
+			[]string{"traffic_group", "device"},
+			nil,
+		),
+		trafficGroupNextDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "traffic_group_next_active"),
+			"traffic_group_next_active",
+			[]string{"traffic_group", "device"},
+			nil,
+		),
+		deviceInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "device_info"),
+			"device_info",
+			[]string{"device", "hostname", "management_ip", "version", "build", "failover_state", "self"},
+			nil,
+		),
+		deviceTrustedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "device_trusted"),
+			"device_trusted",
+			[]string{"device"},
+			nil,
+		),
+		deviceConnectedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "device_connected"),
+			"device_connected",
+			[]string{"device"},
+			nil,
+		),
+		collectorScrapeStatus: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_status",
+				Help:      "collector_scrape_status",
+			},
+			[]string{"collector"},
+		),
+		collectorScrapeDuration: prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_duration",
+				Help:      "collector_scrape_duration",
+			},
+			[]string{"collector"},
+		),
+		client: client,
+	}, nil
+}
+
+// Collect collects metrics for BIG-IP traffic groups and device trust.
+func (c *CMCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	err := c.collectTrafficGroups(ch)
+	if err != nil {
+		logger.Warningf("Failed to get traffic groups (%s)", err)
+	}
+	if deviceErr := c.collectDevices(ch); deviceErr != nil {
+		logger.Warningf("Failed to get devices (%s)", deviceErr)
+		err = deviceErr
+	}
+	if err != nil {
+		c.collectorScrapeStatus.WithLabelValues("cm").Set(float64(0))
+	} else {
+		c.collectorScrapeStatus.WithLabelValues("cm").Set(float64(1))
+		logger.Debugf("Successfully fetched traffic groups and devices")
+	}
+
+	elapsed := time.Since(start)
+	c.collectorScrapeDuration.WithLabelValues("cm").Observe(float64(elapsed.Seconds()))
+	c.collectorScrapeStatus.Collect(ch)
+	c.collectorScrapeDuration.Collect(ch)
+	logger.Debugf("Getting traffic groups and devices took %s", elapsed)
+}
+
+// collectTrafficGroups exports the configuration of each traffic group and,
+// for each device, whether the traffic group is active or next active on it.
+func (c *CMCollector) collectTrafficGroups(ch chan<- prometheus.Metric) error {
+	var trafficGroups trafficGroupsResponse
+	if err := c.client.get("/mgmt/tm/cm/traffic-group", &trafficGroups); err != nil {
+		return err
+	}
+	for _, tg := range trafficGroups.Items {
+		haOrder := make([]string, 0, len(tg.HAOrder))
+		for _, device := range tg.HAOrder {
+			_, name := splitFullPath(device)
+			haOrder = append(haOrder, name)
+		}
+		ch <- prometheus.MustNewConstMetric(c.trafficGroupInfoDesc, prometheus.GaugeValue, 1, tg.Name, tg.FailoverMethod, strings.Join(haOrder, ","), tg.AutoFailbackEnabled)
+	}
+
+	var tgStats statsResponse
+	if err := c.client.get("/mgmt/tm/cm/traffic-group/stats", &tgStats); err != nil {
+		return err
+	}
+	for _, stats := range tgStats.Entries {
+		entries := stats.NestedStats.Entries
+		_, tgName := splitFullPath(entries.description("trafficGroup"))
+		_, device := splitFullPath(entries.description("deviceName"))
+		ch <- prometheus.MustNewConstMetric(c.trafficGroupActiveDesc, prometheus.GaugeValue, boolToFloat64(entries.description("failoverState") == "active"), tgName, device)
+		ch <- prometheus.MustNewConstMetric(c.trafficGroupNextDesc, prometheus.GaugeValue, boolToFloat64(entries.description("nextActive") == "true"), tgName, device)
+	}
+	return nil
+}
+
+// collectDevices exports the devices known to the target, whether they are
+// in its trust domain and whether the target is connected to them. The
+// connection state is read from the sync status details, which hold lines
+// like "bigip2.example.com: connected (for 3600 seconds)".
+func (c *CMCollector) collectDevices(ch chan<- prometheus.Metric) error {
+	var devices devicesResponse
+	if err := c.client.get("/mgmt/tm/cm/device", &devices); err != nil {
+		return err
+	}
+
+	var trustDomains trustDomainsResponse
+	if err := c.client.get("/mgmt/tm/cm/trust-domain", &trustDomains); err != nil {
+		return err
+	}
+	trusted := make(map[string]bool)
+	for _, td := range trustDomains.Items {
+		for _, device := range append(td.CADevices, td.NonCADevices...) {
+			_, name := splitFullPath(device)
+			trusted[name] = true
+		}
+	}
+
+	var syncStatus statsResponse
+	if err := c.client.get("/mgmt/tm/cm/sync-status", &syncStatus); err != nil {
+		return err
+	}
+	// The details name the peers by full path, e.g.
+	// "/Common/bigip2.example.com: connected (for 3600 seconds)".
+	connected := make(map[string]bool)
+	for _, status := range syncStatus.Entries {
+		for key, details := range status.NestedStats.Entries {
+			if !strings.HasSuffix(key, "/details") {
+				continue
+			}
+			for _, detail := range details.NestedStats.Entries {
+				parts := strings.SplitN(detail.NestedStats.Entries.description("details"), ": ", 2)
+				if len(parts) == 2 {
+					_, name := splitFullPath(parts[0])
+					connected[name] = strings.HasPrefix(parts[1], "connected")
+				}
+			}
+		}
+	}
+
+	for _, device := range devices.Items {
+		self := device.SelfDevice == "true"
+		ch <- prometheus.MustNewConstMetric(c.deviceInfoDesc, prometheus.GaugeValue, 1,
+			device.Name, device.Hostname, device.ManagementIP, device.Version, device.Build, device.FailoverState, device.SelfDevice)
+		ch <- prometheus.MustNewConstMetric(c.deviceTrustedDesc, prometheus.GaugeValue, boolToFloat64(trusted[device.Name]), device.Name)
+		if !self {
+			ch <- prometheus.MustNewConstMetric(c.deviceConnectedDesc, prometheus.GaugeValue, boolToFloat64(connected[device.Name] || connected[device.Hostname]), device.Name)
+		}
+	}
+	return nil
+}
+
+// Describe describes the metrics exported from this collector.
+func (c *CMCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.trafficGroupInfoDesc
+	ch <- c.trafficGroupActiveDesc
+	ch <- c.trafficGroupNextDesc
+	ch <- c.deviceInfoDesc
+	ch <- c.deviceTrustedDesc
+	ch <- c.deviceConnectedDesc
+	c.collectorScrapeStatus.Describe(ch)
+	c.collectorScrapeDuration.Describe(ch)
+}
@@ -0,0 +1,65 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestCollectDevicesConnected(t *testing.T) {
+	client, done := newTestClientForPaths(t, map[string]string{
+		"/mgmt/tm/cm/device": `{"items":[` +
+			`{"name":"bigip1.example.com","hostname":"bigip1.example.com","managementIp":"192.0.2.1","selfDevice":"true"},` +
+			`{"name":"bigip2.example.com","hostname":"bigip2.example.com","managementIp":"192.0.2.2","selfDevice":"false"},` +
+			`{"name":"bigip3","hostname":"bigip3.example.com","managementIp":"192.0.2.3","selfDevice":"false"},` +
+			`{"name":"bigip4.example.com","hostname":"bigip4.example.com","managementIp":"192.0.2.4","selfDevice":"false"}]}`,
+		"/mgmt/tm/cm/trust-domain": `{"items":[{"caDevices":["/Common/bigip1.example.com","/Common/bigip2.example.com","/Common/bigip3","/Common/bigip4.example.com"]}]}`,
+		"/mgmt/tm/cm/sync-status": `{"entries":{"https://localhost/mgmt/tm/cm/sync-status/0":{"nestedStats":{"entries":{` +
+			`"color":{"description":"green"},` +
+			`"https://localhost/mgmt/tm/cm/syncStatus/0/details":{"nestedStats":{"entries":{` +
+			`"https://localhost/mgmt/tm/cm/syncStatus/0/details/0":{"nestedStats":{"entries":{"details":{"description":"/Common/bigip2.example.com: connected (for 3600 seconds)"}}}},` +
+			`"https://localhost/mgmt/tm/cm/syncStatus/0/details/1":{"nestedStats":{"entries":{"details":{"description":"/Common/bigip3.example.com: connected (for 120 seconds)"}}}},` +
+			`"https://localhost/mgmt/tm/cm/syncStatus/0/details/2":{"nestedStats":{"entries":{"details":{"description":"/Common/bigip4.example.com: disconnected"}}}},` +
+			`"https://localhost/mgmt/tm/cm/syncStatus/0/details/3":{"nestedStats":{"entries":{"details":{"description":"/Common/device_trust_group (In Sync): All devices in the device group are in sync"}}}}` +
+			`}}}}}}}}`,
+	})
+	defer done()
+
+	c, _ := NewCMCollector(client, "bigip")
+	ch := make(chan prometheus.Metric, 100)
+	if err := c.collectDevices(ch); err != nil {
+		t.Fatal(err)
+	}
+	close(ch)
+
+	connected := make(map[string]float64)
+	for m := range ch {
+		if m.Desc() != c.deviceConnectedDesc {
+			continue
+		}
+		var metric dto.Metric
+		if err := m.Write(&metric); err != nil {
+			t.Fatal(err)
+		}
+		connected[metric.GetLabel()[0].GetValue()] = metric.GetGauge().GetValue()
+	}
+	want := map[string]float64{
+		"bigip2.example.com": 1,
+		"bigip3":             1,
+		"bigip4.example.com": 0,
+	}
+	for device, value := range want {
+		got, ok := connected[device]
+		if !ok {
+			t.Errorf("%s: no connected metric", device)
+			continue
+		}
+		if got != value {
+			t.Errorf("%s: got connected %v, want %v", device, got, value)
+		}
+	}
+	if _, ok := connected["bigip1.example.com"]; ok {
+		t.Error("bigip1.example.com: got a connected metric for the target itself")
+	}
+}
@@ -1,5 +1,11 @@
 package collector
 
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
 func stringInSlice(a string, list []string) bool {
 	for _, b := range list {
 		if b == a {
@@ -8,3 +14,31 @@ func stringInSlice(a string, list []string) bool {
 	}
 	return false
 }
+
+// toSnakeCase converts a BIG-IP stat name like ecdheRsa or tlsv1_2 to
+// snake case for use in metric and label names.
+func toSnakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func boolToFloat64(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// formatPort formats a port number read from a stats value.
+func formatPort(port float64) string {
+	return strconv.Itoa(int(port))
+}
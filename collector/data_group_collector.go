@@ -0,0 +1,187 @@
+package collector
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// A DataGroupCollector implements the prometheus.Collector.
+type DataGroupCollector struct {
+	recordsDesc             *prometheus.Desc
+	externalFileSizeDesc    *prometheus.Desc
+	ifileSizeDesc           *prometheus.Desc
+	client                  *Client
+	partitionsList          []string
+	collectorScrapeStatus   *prometheus.GaugeVec
+	collectorScrapeDuration *prometheus.SummaryVec
+}
+
+type internalDataGroupsResponse struct {
+	Items []struct {
+		Name      string `json:"name"`
+		Partition string `json:"partition"`
+		FullPath  string `json:"fullPath"`
+		Type      string `json:"type"`
+	} `json:"items"`
+}
+
+// internalDataGroupResponse holds the records of one internal data group.
+// Only their number is needed, so their names and data are not decoded.
+type internalDataGroupResponse struct {
+	Records []struct{} `json:"records"`
+}
+
+type externalDataGroupsResponse struct {
+	Items []struct {
+		Name             string `json:"name"`
+		Partition        string `json:"partition"`
+		ExternalFileName string `json:"externalFileName"`
+	} `json:"items"`
+}
+
+// sysFilesResponse is the body of the /mgmt/tm/sys/file collections, which
+// hold the files behind external data groups and iFiles.
+type sysFilesResponse struct {
+	Items []struct {
+		Name      string `json:"name"`
+		Partition string `json:"partition"`
+		FullPath  string `json:"fullPath"`
+		Type      string `json:"type"`
+		Size      int64  `json:"size"`
+	} `json:"items"`
+}
+
+// NewDataGroupCollector returns a collector that collecting data group and iFile sizes
+func NewDataGroupCollector(client *Client, namespace string, partitionsList []string) (*DataGroupCollector, error) {
+	subsystem := "data_group"
+	return &DataGroupCollector{
+		recordsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "records"),
+			"records",
+			[]string{"partition", "data_group", "type"},
+			nil,
+		),
+		externalFileSizeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "external_file_size_bytes"),
+			"external_file_size_bytes",
+			[]string{"partition", "data_group", "type", "file"},
+			nil,
+		),
+		ifileSizeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ifile", "size_bytes"),
+			"size_bytes",
+			[]string{"partition", "ifile"},
+			nil,
+		),
+		collectorScrapeStatus: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_status",
+				Help:      "collector_scrape_status",
+			},
+			[]string{"collector"},
+		),
+		collectorScrapeDuration: prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_duration",
+				Help:      "collector_scrape_duration",
+			},
+			[]string{"collector"},
+		),
+		client:         client,
+		partitionsList: partitionsList,
+	}, nil
+}
+
+// Collect collects metrics for BIG-IP data groups and iFiles.
+func (c *DataGroupCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	err := c.collect(ch)
+	if err != nil {
+		c.collectorScrapeStatus.WithLabelValues("data_group").Set(float64(0))
+		logger.Warningf("Failed to get data groups (%s)", err)
+	} else {
+		c.collectorScrapeStatus.WithLabelValues("data_group").Set(float64(1))
+		logger.Debugf("Successfully fetched data groups")
+	}
+
+	elapsed := time.Since(start)
+	c.collectorScrapeDuration.WithLabelValues("data_group").Observe(float64(elapsed.Seconds()))
+	c.collectorScrapeStatus.Collect(ch)
+	c.collectorScrapeDuration.Collect(ch)
+	logger.Debugf("Getting data groups took %s", elapsed)
+}
+
+// collect exports the number of records of each internal data group. The
+// records of external data groups are only held in their file, so the size
+// of the file is exported instead.
+//
+// iControl REST cannot count records, so they have to be downloaded. The
+// data groups are listed without their records and then read one at a
+// time, so that a scrape never holds the records of all data groups and
+// skips those of partitions that are not collected.
+func (c *DataGroupCollector) collect(ch chan<- prometheus.Metric) error {
+	var internal internalDataGroupsResponse
+	if err := c.client.get("/mgmt/tm/ltm/data-group/internal?$select=name,partition,fullPath,type", &internal); err != nil {
+		return err
+	}
+	for _, dg := range internal.Items {
+		if c.partitionsList != nil && !stringInSlice(dg.Partition, c.partitionsList) {
+			continue
+		}
+		var records internalDataGroupResponse
+		path := "/mgmt/tm/ltm/data-group/internal/" + strings.Replace(dg.FullPath, "/", "~", -1) + "?$select=records"
+		if err := c.client.get(path, &records); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(c.recordsDesc, prometheus.GaugeValue, float64(len(records.Records)), dg.Partition, dg.Name, dg.Type)
+	}
+
+	var external externalDataGroupsResponse
+	if err := c.client.get("/mgmt/tm/ltm/data-group/external", &external); err != nil {
+		return err
+	}
+	var files sysFilesResponse
+	if err := c.client.get("/mgmt/tm/sys/file/data-group", &files); err != nil {
+		return err
+	}
+	fileIndex := make(map[string]int)
+	for i, file := range files.Items {
+		fileIndex[file.FullPath] = i
+	}
+	for _, dg := range external.Items {
+		if c.partitionsList != nil && !stringInSlice(dg.Partition, c.partitionsList) {
+			continue
+		}
+		i, ok := fileIndex[dg.ExternalFileName]
+		if !ok {
+			continue
+		}
+		file := files.Items[i]
+		ch <- prometheus.MustNewConstMetric(c.externalFileSizeDesc, prometheus.GaugeValue, float64(file.Size), dg.Partition, dg.Name, file.Type, file.FullPath)
+	}
+
+	var ifiles sysFilesResponse
+	if err := c.client.get("/mgmt/tm/sys/file/ifile", &ifiles); err != nil {
+		return err
+	}
+	for _, ifile := range ifiles.Items {
+		if c.partitionsList != nil && !stringInSlice(ifile.Partition, c.partitionsList) {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.ifileSizeDesc, prometheus.GaugeValue, float64(ifile.Size), ifile.Partition, ifile.Name)
+	}
+	return nil
+}
+
+// Describe describes the metrics exported from this collector.
+func (c *DataGroupCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.recordsDesc
+	ch <- c.externalFileSizeDesc
+	ch <- c.ifileSizeDesc
+	c.collectorScrapeStatus.Describe(ch)
+	c.collectorScrapeDuration.Describe(ch)
+}
@@ -0,0 +1,54 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestCollectDataGroupRecords(t *testing.T) {
+	client, done := newTestClientForPaths(t, map[string]string{
+		"/mgmt/tm/ltm/data-group/internal": `{"items":[` +
+			`{"name":"allowed_hosts","partition":"Common","fullPath":"/Common/allowed_hosts","type":"string"},` +
+			`{"name":"empty","partition":"Common","fullPath":"/Common/empty","type":"ip"},` +
+			`{"name":"redirects","partition":"app","fullPath":"/app/redirects","type":"string"}]}`,
+		"/mgmt/tm/ltm/data-group/internal/~Common~allowed_hosts": `{"records":[{"name":"a.example.com","data":""},{"name":"b.example.com"}]}`,
+		"/mgmt/tm/ltm/data-group/internal/~Common~empty":         `{}`,
+		"/mgmt/tm/ltm/data-group/external":                       `{"items":[]}`,
+		"/mgmt/tm/sys/file/data-group":                           `{"items":[]}`,
+		"/mgmt/tm/sys/file/ifile":                                `{"items":[]}`,
+	})
+	defer done()
+
+	// The records of /app/redirects are not served, so reading them would
+	// fail the scrape.
+	c, _ := NewDataGroupCollector(client, "bigip", []string{"Common"})
+	ch := make(chan prometheus.Metric, 100)
+	if err := c.collect(ch); err != nil {
+		t.Fatal(err)
+	}
+	close(ch)
+
+	records := make(map[string]float64)
+	for m := range ch {
+		var metric dto.Metric
+		if err := m.Write(&metric); err != nil {
+			t.Fatal(err)
+		}
+		for _, label := range metric.GetLabel() {
+			if label.GetName() == "data_group" {
+				records[label.GetValue()] = metric.GetGauge().GetValue()
+			}
+		}
+	}
+	want := map[string]float64{"allowed_hosts": 2, "empty": 0}
+	if len(records) != len(want) {
+		t.Errorf("got records %v, want %v", records, want)
+	}
+	for dg, value := range want {
+		if got, ok := records[dg]; !ok || got != value {
+			t.Errorf("%s: got %v records, want %v", dg, got, value)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package collector
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// A HardwareCollector implements the prometheus.Collector.
+type HardwareCollector struct {
+	fanSpeedDesc            *prometheus.Desc
+	fanUpDesc               *prometheus.Desc
+	powerSupplyUpDesc       *prometheus.Desc
+	chassisTemperatureDesc  *prometheus.Desc
+	bladeTemperatureDesc    *prometheus.Desc
+	platformInfoDesc        *prometheus.Desc
+	client                  *Client
+	collectorScrapeStatus   *prometheus.GaugeVec
+	collectorScrapeDuration *prometheus.SummaryVec
+}
+
+// NewHardwareCollector returns a collector that collecting hardware sensors
+func NewHardwareCollector(client *Client, namespace string) (*HardwareCollector, error) {
+	subsystem := "hardware"
+	return &HardwareCollector{
+		fanSpeedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "fan_speed_rpm"),
+			"fan_speed_rpm",
+			[]string{"index"},
+			nil,
+		),
+		fanUpDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "fan_up"),
+			"fan_up",
+			[]string{"index"},
+			nil,
+		),
+		powerSupplyUpDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "power_supply_up"),
+			"power_supply_up",
+			[]string{"index"},
+			nil,
+		),
+		chassisTemperatureDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "chassis_temperature_celsius"),
+			"chassis_temperature_celsius",
+			[]string{"index"},
+			nil,
+		),
+		bladeTemperatureDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "blade_temperature_celsius"),
+			"blade_temperature_celsius",
+			[]string{"slot", "location"},
+			nil,
+		),
+		platformInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "platform_info"),
+			"platform_info",
+			[]string{"platform", "marketing_name", "product", "serial"},
+			nil,
+		),
+		collectorScrapeStatus: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_status",
+				Help:      "collector_scrape_status",
+			},
+			[]string{"collector"},
+		),
+		collectorScrapeDuration: prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_duration",
+				Help:      "collector_scrape_duration",
+			},
+			[]string{"collector"},
+		),
+		client: client,
+	}, nil
+}
+
+// Collect collects metrics for BIG-IP hardware. Virtual Edition has no
+// sensors, so nothing is exported there.
+func (c *HardwareCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	hardware, err := c.client.hardware()
+	if err != nil {
+		c.collectorScrapeStatus.WithLabelValues("hardware").Set(float64(0))
+		logger.Warningf("Failed to get hardware (%s)", err)
+	} else {
+		var platform, marketingName, product, serial string
+		sensors := false
+		for key, section := range hardware.Entries {
+			for _, item := range section.NestedStats.Entries {
+				entries := item.NestedStats.Entries
+				index := fmt.Sprint(entries.value("index"))
+				if strings.HasSuffix(key, "-status-index") {
+					sensors = true
+				}
+				switch {
+				case strings.HasSuffix(key, "/chassis-fan-status-index"):
+					ch <- prometheus.MustNewConstMetric(c.fanSpeedDesc, prometheus.GaugeValue, entries.value("fanSpeed"), index)
+					ch <- prometheus.MustNewConstMetric(c.fanUpDesc, prometheus.GaugeValue, boolToFloat64(entries.description("status") == "up"), index)
+				case strings.HasSuffix(key, "/chassis-power-supply-status-index"):
+					ch <- prometheus.MustNewConstMetric(c.powerSupplyUpDesc, prometheus.GaugeValue, boolToFloat64(entries.description("status") == "up"), index)
+				case strings.HasSuffix(key, "/chassis-temperature-status-index"):
+					ch <- prometheus.MustNewConstMetric(c.chassisTemperatureDesc, prometheus.GaugeValue, entries.value("temperature"), index)
+				case strings.HasSuffix(key, "/blade-temperature-status-index"):
+					ch <- prometheus.MustNewConstMetric(c.bladeTemperatureDesc, prometheus.GaugeValue, entries.value("temperature"),
+						fmt.Sprint(entries.value("slot")), entries.description("location"))
+				case strings.HasSuffix(key, "/platform"):
+					platform = entries.description("name")
+					marketingName = entries.description("marketingName")
+				case strings.HasSuffix(key, "/system-info"):
+					product = entries.description("product")
+					serial = entries.description("bigipChassisSerialNum")
+				}
+			}
+		}
+		if sensors {
+			ch <- prometheus.MustNewConstMetric(c.platformInfoDesc, prometheus.GaugeValue, 1, platform, marketingName, product, serial)
+		}
+		c.collectorScrapeStatus.WithLabelValues("hardware").Set(float64(1))
+		logger.Debugf("Successfully fetched hardware")
+	}
+
+	elapsed := time.Since(start)
+	c.collectorScrapeDuration.WithLabelValues("hardware").Observe(float64(elapsed.Seconds()))
+	c.collectorScrapeStatus.Collect(ch)
+	c.collectorScrapeDuration.Collect(ch)
+	logger.Debugf("Getting hardware took %s", elapsed)
+}
+
+// Describe describes the metrics exported from this collector.
+func (c *HardwareCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.fanSpeedDesc
+	ch <- c.fanUpDesc
+	ch <- c.powerSupplyUpDesc
+	ch <- c.chassisTemperatureDesc
+	ch <- c.bladeTemperatureDesc
+	ch <- c.platformInfoDesc
+	c.collectorScrapeStatus.Describe(ch)
+	c.collectorScrapeDuration.Describe(ch)
+}
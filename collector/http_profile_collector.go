@@ -0,0 +1,428 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// A HTTPProfileCollector implements the prometheus.Collector.
+type HTTPProfileCollector struct {
+	metrics                 map[string]statsMetric
+	vsProfileDesc           *prometheus.Desc
+	client                  *Client
+	partitionsList          []string
+	collectorScrapeStatus   *prometheus.GaugeVec
+	collectorScrapeDuration *prometheus.SummaryVec
+}
+
+// NewHTTPProfileCollector returns a collector that collecting http profile statistics
+func NewHTTPProfileCollector(client *Client, namespace string, partitionsList []string) (*HTTPProfileCollector, error) {
+	var (
+		subsystem  = "http_profile"
+		labelNames = []string{"partition", "profile"}
+	)
+	return &HTTPProfileCollector{
+		metrics: map[string]statsMetric{
+			"numberReqs": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "number_reqs"),
+					"number_reqs",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("numberReqs")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"getReqs": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "get_reqs"),
+					"get_reqs",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("getReqs")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"postReqs": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "post_reqs"),
+					"post_reqs",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("postReqs")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"v9Reqs": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "v9_reqs"),
+					"v9_reqs",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("v9Reqs")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"v10Reqs": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "v10_reqs"),
+					"v10_reqs",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("v10Reqs")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"v11Reqs": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "v11_reqs"),
+					"v11_reqs",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("v11Reqs")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"v9Resp": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "v9_resp"),
+					"v9_resp",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("v9Resp")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"v10Resp": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "v10_resp"),
+					"v10_resp",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("v10Resp")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"v11Resp": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "v11_resp"),
+					"v11_resp",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("v11Resp")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"resp_2xxCnt": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "resp_2xx_cnt"),
+					"resp_2xx_cnt",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("resp_2xxCnt")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"resp_3xxCnt": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "resp_3xx_cnt"),
+					"resp_3xx_cnt",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("resp_3xxCnt")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"resp_4xxCnt": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "resp_4xx_cnt"),
+					"resp_4xx_cnt",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("resp_4xxCnt")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"resp_5xxCnt": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "resp_5xx_cnt"),
+					"resp_5xx_cnt",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("resp_5xxCnt")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			// BIG-IP only buckets responses by size, requests are not
+			// bucketed.
+			"respBucket_1k": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "resp_bucket_1k"),
+					"resp_bucket_1k",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("respBucket_1k")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"respBucket_4k": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "resp_bucket_4k"),
+					"resp_bucket_4k",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("respBucket_4k")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"respBucket_16k": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "resp_bucket_16k"),
+					"resp_bucket_16k",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("respBucket_16k")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"respBucket_32k": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "resp_bucket_32k"),
+					"resp_bucket_32k",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("respBucket_32k")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"respBucket_64k": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "resp_bucket_64k"),
+					"resp_bucket_64k",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("respBucket_64k")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"maxKeepaliveReq": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "max_keepalive_req"),
+					"max_keepalive_req",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("maxKeepaliveReq")
+				},
+				valueType: prometheus.GaugeValue,
+			},
+			"pipelinedReqs": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "pipelined_reqs"),
+					"pipelined_reqs",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("pipelinedReqs")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"passthroughPipeline": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "passthrough_pipeline"),
+					"passthrough_pipeline",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("passthroughPipeline")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"passthroughWebSockets": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "passthrough_web_sockets"),
+					"passthrough_web_sockets",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("passthroughWebSockets")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"proxyReqs": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "proxy_reqs"),
+					"proxy_reqs",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("proxyReqs")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"proxyConnReqs": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "proxy_conn_reqs"),
+					"proxy_conn_reqs",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("proxyConnReqs")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"cookiePersistInserts": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "cookie_persist_inserts"),
+					"cookie_persist_inserts",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("cookiePersistInserts")
+				},
+				valueType: prometheus.CounterValue,
+			},
+		},
+		vsProfileDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "vs_info"),
+			"vs_info",
+			[]string{"partition", "vs", "profile_partition", "profile"},
+			nil,
+		),
+		collectorScrapeStatus: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_status",
+				Help:      "collector_scrape_status",
+			},
+			[]string{"collector"},
+		),
+		collectorScrapeDuration: prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_duration",
+				Help:      "collector_scrape_duration",
+			},
+			[]string{"collector"},
+		),
+		client:         client,
+		partitionsList: partitionsList,
+	}, nil
+}
+
+// Collect collects metrics for BIG-IP http profiles.
+func (c *HTTPProfileCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	var allProfileStats statsResponse
+	err := c.client.get("/mgmt/tm/ltm/profile/http/stats", &allProfileStats)
+	if err != nil {
+		c.collectorScrapeStatus.WithLabelValues("http_profile").Set(float64(0))
+		logger.Warningf("Failed to get statistics for http profiles (%s)", err)
+	} else {
+		profiles := make(map[string]bool)
+		for _, profileStats := range allProfileStats.Entries {
+			entries := profileStats.NestedStats.Entries
+			fullPath := entries.description("tmName")
+			partition, profileName := splitFullPath(fullPath)
+
+			if c.partitionsList != nil && !stringInSlice(partition, c.partitionsList) {
+				continue
+			}
+			profiles[fullPath] = true
+
+			labels := []string{partition, profileName}
+			for _, metric := range c.metrics {
+				ch <- prometheus.MustNewConstMetric(metric.desc, metric.valueType, metric.extract(entries), labels...)
+			}
+		}
+
+		if err := c.collectVirtualServers(ch, profiles); err != nil {
+			c.collectorScrapeStatus.WithLabelValues("http_profile").Set(float64(0))
+			logger.Warningf("Failed to get http profiles of virtual servers (%s)", err)
+		} else {
+			c.collectorScrapeStatus.WithLabelValues("http_profile").Set(float64(1))
+			logger.Debugf("Successfully fetched statistics for http profiles")
+		}
+	}
+
+	elapsed := time.Since(start)
+	c.collectorScrapeDuration.WithLabelValues("http_profile").Observe(float64(elapsed.Seconds()))
+	c.collectorScrapeStatus.Collect(ch)
+	c.collectorScrapeDuration.Collect(ch)
+	logger.Debugf("Getting http profile statistics took %s", elapsed)
+}
+
+// collectVirtualServers exports which of the given http profiles are attached
+// to which virtual server.
+func (c *HTTPProfileCollector) collectVirtualServers(ch chan<- prometheus.Metric, profiles map[string]bool) error {
+	virtualServers, err := c.client.virtualServers()
+	if err != nil {
+		return err
+	}
+	for _, vs := range virtualServers {
+		if c.partitionsList != nil && !stringInSlice(vs.Partition, c.partitionsList) {
+			continue
+		}
+		for _, profile := range vs.ProfilesReference.Items {
+			if !profiles[profile.FullPath] {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(c.vsProfileDesc, prometheus.GaugeValue, 1, vs.Partition, vs.Name, profile.Partition, profile.Name)
+		}
+	}
+	return nil
+}
+
+// Describe describes the metrics exported from this collector.
+func (c *HTTPProfileCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, metric := range c.metrics {
+		ch <- metric.desc
+	}
+	ch <- c.vsProfileDesc
+	c.collectorScrapeStatus.Describe(ch)
+	c.collectorScrapeDuration.Describe(ch)
+}
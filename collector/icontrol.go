@@ -0,0 +1,195 @@
+package collector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// statsResponse is the body returned by the iControl REST stats endpoints,
+// e.g. /mgmt/tm/ltm/profile/http/stats. Entries are keyed by the self link of
+// each object.
+type statsResponse struct {
+	Entries map[string]statsEntry `json:"entries"`
+}
+
+// A statsEntry holds a value, a description or nested stats.
+type statsEntry struct {
+	Value       float64 `json:"value"`
+	Description string  `json:"description"`
+	NestedStats struct {
+		Entries statsEntries `json:"entries"`
+	} `json:"nestedStats"`
+}
+
+type statsEntries map[string]statsEntry
+
+// value returns the value of the named stat, or 0 if it is missing.
+func (e statsEntries) value(name string) float64 {
+	return e[name].Value
+}
+
+// description returns the description of the named stat.
+func (e statsEntries) description(name string) string {
+	return e[name].Description
+}
+
+// A statsMetric extracts one metric from the stats of an object.
+type statsMetric struct {
+	desc      *prometheus.Desc
+	extract   func(statsEntries) float64
+	valueType prometheus.ValueType
+}
+
+// virtualServersResponse is the body of
+// /mgmt/tm/ltm/virtual?expandSubcollections=true.
+type virtualServersResponse struct {
+	Items []virtualServer `json:"items"`
+}
+
+type virtualServer struct {
+	Name              string   `json:"name"`
+	Partition         string   `json:"partition"`
+	FullPath          string   `json:"fullPath"`
+	Destination       string   `json:"destination"`
+	Rules             []string `json:"rules"`
+	ProfilesReference struct {
+		Items []struct {
+			Name      string `json:"name"`
+			Partition string `json:"partition"`
+			FullPath  string `json:"fullPath"`
+			Context   string `json:"context"`
+		} `json:"items"`
+	} `json:"profilesReference"`
+	PoliciesReference struct {
+		Items []struct {
+			Name      string `json:"name"`
+			Partition string `json:"partition"`
+			FullPath  string `json:"fullPath"`
+		} `json:"items"`
+	} `json:"policiesReference"`
+}
+
+// virtualServers returns the configuration of all virtual servers including
+// their attached profiles and policies. It is read once and shared by the
+// collectors.
+func (c *Client) virtualServers() ([]virtualServer, error) {
+	c.virtualServersMu.Lock()
+	defer c.virtualServersMu.Unlock()
+	if !c.virtualServersFetched {
+		var resp virtualServersResponse
+		c.virtualServersErr = c.get("/mgmt/tm/ltm/virtual?expandSubcollections=true", &resp)
+		c.virtualServersItems = resp.Items
+		c.virtualServersFetched = true
+	}
+	return c.virtualServersItems, c.virtualServersErr
+}
+
+// destinationsByAddress maps the destination address and port of each
+// virtual server, as written in persistence records and the connection table,
+// to the virtual server.
+func destinationsByAddress(virtualServers []virtualServer) map[string]virtualServer {
+	destinations := make(map[string]virtualServer)
+	for _, vs := range virtualServers {
+		_, destination := splitFullPath(vs.Destination)
+		destinations[stripRouteDomain(destination)] = vs
+	}
+	return destinations
+}
+
+// stripRouteDomain removes the route domain from an address and port, e.g.
+// 10.0.0.1%2:80 becomes 10.0.0.1:80.
+func stripRouteDomain(addrPort string) string {
+	if i := strings.Index(addrPort, "%"); i >= 0 {
+		if j := strings.LastIndexAny(addrPort, ":."); j > i {
+			return addrPort[:i] + addrPort[j:]
+		}
+		return addrPort[:i]
+	}
+	return addrPort
+}
+
+// routeDomainOf returns the route domain id of an address like
+// 10.0.0.0%2/24, or 0 if the address has none.
+func routeDomainOf(addr string) string {
+	i := strings.Index(addr, "%")
+	if i < 0 {
+		return "0"
+	}
+	id := addr[i+1:]
+	if j := strings.IndexAny(id, "/:."); j >= 0 {
+		id = id[:j]
+	}
+	return id
+}
+
+// joinHostPort joins an address and port the way BIG-IP writes
+// destinations: IPv6 addresses use a dot before the port.
+func joinHostPort(addr, port string) string {
+	if strings.Contains(addr, ":") {
+		return addr + "." + port
+	}
+	return addr + ":" + port
+}
+
+// splitFullPath splits a full path like /Common/app.app/name into its
+// partition and object name.
+func splitFullPath(fullPath string) (string, string) {
+	parts := strings.Split(strings.TrimPrefix(fullPath, "/"), "/")
+	if len(parts) < 2 {
+		return "", fullPath
+	}
+	return parts[0], parts[len(parts)-1]
+}
+
+// parseTimestamp converts a timestamp like 2019-09-27T14:47:51Z to seconds
+// since the epoch. It reports false if s is empty or not a timestamp.
+func parseTimestamp(s string) (float64, bool) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, false
+	}
+	return float64(t.Unix()), true
+}
+
+// cpuMHz returns the clock speed of the CPUs of the BIG-IP. It is listed in
+// /mgmt/tm/sys/hardware as the "cpu MHz" version of the cpus hardware.
+func (c *Client) cpuMHz() (float64, error) {
+	hardware, err := c.hardware()
+	if err != nil {
+		return 0, err
+	}
+	for key, section := range hardware.Entries {
+		if !strings.HasSuffix(key, "/hardware-version") {
+			continue
+		}
+		for _, item := range section.NestedStats.Entries {
+			entries := item.NestedStats.Entries
+			if entries.description("name") != "cpus" {
+				continue
+			}
+			for versionsKey, versions := range entries {
+				if !strings.HasSuffix(versionsKey, "/versions") {
+					continue
+				}
+				for _, version := range versions.NestedStats.Entries {
+					if version.NestedStats.Entries.description("name") != "cpu MHz" {
+						continue
+					}
+					mhz, err := strconv.ParseFloat(version.NestedStats.Entries.description("version"), 64)
+					if err != nil {
+						return 0, err
+					}
+					if mhz <= 0 {
+						return 0, fmt.Errorf("invalid cpu MHz %v in /mgmt/tm/sys/hardware", mhz)
+					}
+					return mhz, nil
+				}
+			}
+		}
+	}
+	return 0, fmt.Errorf("no cpu MHz in /mgmt/tm/sys/hardware")
+}
@@ -0,0 +1,165 @@
+package collector
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// throughputRegexp matches the licensed throughput in the name of an active
+// module, e.g. "BIG-IP, VE, 1 Gbps, BEST".
+var throughputRegexp = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*([MG])bps`)
+
+// A LicenseCollector implements the prometheus.Collector.
+type LicenseCollector struct {
+	endDateDesc             *prometheus.Desc
+	serviceCheckDateDesc    *prometheus.Desc
+	infoDesc                *prometheus.Desc
+	moduleThroughputDesc    *prometheus.Desc
+	client                  *Client
+	collectorScrapeStatus   *prometheus.GaugeVec
+	collectorScrapeDuration *prometheus.SummaryVec
+}
+
+// NewLicenseCollector returns a collector that collecting the license
+func NewLicenseCollector(client *Client, namespace string) (*LicenseCollector, error) {
+	subsystem := "license"
+	return &LicenseCollector{
+		endDateDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "end_date_timestamp_seconds"),
+			"end_date_timestamp_seconds",
+			nil,
+			nil,
+		),
+		serviceCheckDateDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "service_check_date_timestamp_seconds"),
+			"service_check_date_timestamp_seconds",
+			nil,
+			nil,
+		),
+		infoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "info"),
+			"info",
+			[]string{"registration_key", "platform_id"},
+			nil,
+		),
+		moduleThroughputDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "module_throughput_bits_per_second"),
+			"module_throughput_bits_per_second",
+			[]string{"module"},
+			nil,
+		),
+		collectorScrapeStatus: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_status",
+				Help:      "collector_scrape_status",
+			},
+			[]string{"collector"},
+		),
+		collectorScrapeDuration: prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_duration",
+				Help:      "collector_scrape_duration",
+			},
+			[]string{"collector"},
+		),
+		client: client,
+	}, nil
+}
+
+// Collect collects metrics for the BIG-IP license. A perpetual license has
+// no end date, so its end date is not exported.
+func (c *LicenseCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	var license statsResponse
+	err := c.client.get("/mgmt/tm/sys/license", &license)
+	if err != nil {
+		c.collectorScrapeStatus.WithLabelValues("license").Set(float64(0))
+		logger.Warningf("Failed to get license (%s)", err)
+	} else {
+		for _, licenseStats := range license.Entries {
+			entries := licenseStats.NestedStats.Entries
+			if endDate, ok := parseLicenseDate(entries.description("licenseEndDate")); ok {
+				ch <- prometheus.MustNewConstMetric(c.endDateDesc, prometheus.GaugeValue, endDate)
+			}
+			if serviceCheckDate, ok := parseLicenseDate(entries.description("serviceCheckDate")); ok {
+				ch <- prometheus.MustNewConstMetric(c.serviceCheckDateDesc, prometheus.GaugeValue, serviceCheckDate)
+			}
+			ch <- prometheus.MustNewConstMetric(c.infoDesc, prometheus.GaugeValue, 1, entries.description("registrationKey"), entries.description("platformId"))
+
+			for key, modules := range entries {
+				if !strings.HasSuffix(key, "/active-modules") {
+					continue
+				}
+				for moduleKey := range modules.NestedStats.Entries {
+					module := moduleName(moduleKey)
+					if throughput, ok := parseThroughput(module); ok {
+						ch <- prometheus.MustNewConstMetric(c.moduleThroughputDesc, prometheus.GaugeValue, throughput, module)
+					}
+				}
+			}
+			break
+		}
+		c.collectorScrapeStatus.WithLabelValues("license").Set(float64(1))
+		logger.Debugf("Successfully fetched license")
+	}
+
+	elapsed := time.Since(start)
+	c.collectorScrapeDuration.WithLabelValues("license").Observe(float64(elapsed.Seconds()))
+	c.collectorScrapeStatus.Collect(ch)
+	c.collectorScrapeDuration.Collect(ch)
+	logger.Debugf("Getting license took %s", elapsed)
+}
+
+// parseLicenseDate converts a license date like 2020/06/01 to seconds since
+// the epoch.
+func parseLicenseDate(s string) (float64, bool) {
+	t, err := time.Parse("2006/01/02", s)
+	if err != nil {
+		return 0, false
+	}
+	return float64(t.Unix()), true
+}
+
+// moduleName returns the name of an active module from the self link it is
+// keyed by, e.g. .../active-modules/%22BIG-IP,%20VE%22 becomes BIG-IP, VE.
+func moduleName(key string) string {
+	name := key[strings.LastIndex(key, "/")+1:]
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	return strings.Trim(name, `"`)
+}
+
+// parseThroughput returns the licensed throughput in bits per second found
+// in the name of a module.
+func parseThroughput(module string) (float64, bool) {
+	match := throughputRegexp.FindStringSubmatch(module)
+	if match == nil {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	if match[2] == "G" {
+		return value * 1e9, true
+	}
+	return value * 1e6, true
+}
+
+// Describe describes the metrics exported from this collector.
+func (c *LicenseCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.endDateDesc
+	ch <- c.serviceCheckDateDesc
+	ch <- c.infoDesc
+	ch <- c.moduleThroughputDesc
+	c.collectorScrapeStatus.Describe(ch)
+	c.collectorScrapeDuration.Describe(ch)
+}
@@ -0,0 +1,155 @@
+package collector
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// A LTMPolicyCollector implements the prometheus.Collector.
+type LTMPolicyCollector struct {
+	ruleMatchesDesc         *prometheus.Desc
+	policyVSDesc            *prometheus.Desc
+	client                  *Client
+	partitionsList          []string
+	collectorScrapeStatus   *prometheus.GaugeVec
+	collectorScrapeDuration *prometheus.SummaryVec
+}
+
+type ltmPoliciesResponse struct {
+	Items []struct {
+		Name      string `json:"name"`
+		Partition string `json:"partition"`
+		FullPath  string `json:"fullPath"`
+	} `json:"items"`
+}
+
+type policyRuleKey struct {
+	vs, rule string
+}
+
+// NewLTMPolicyCollector returns a collector that collecting LTM policy statistics and attachments
+func NewLTMPolicyCollector(client *Client, namespace string, partitionsList []string) (*LTMPolicyCollector, error) {
+	subsystem := "ltm_policy"
+	return &LTMPolicyCollector{
+		ruleMatchesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "rule_matches_total"),
+			"rule_matches_total",
+			[]string{"partition", "policy", "rule", "vs_partition", "vs"},
+			nil,
+		),
+		policyVSDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "vs_info"),
+			"vs_info",
+			[]string{"partition", "policy", "vs_partition", "vs"},
+			nil,
+		),
+		collectorScrapeStatus: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_status",
+				Help:      "collector_scrape_status",
+			},
+			[]string{"collector"},
+		),
+		collectorScrapeDuration: prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_duration",
+				Help:      "collector_scrape_duration",
+			},
+			[]string{"collector"},
+		),
+		client:         client,
+		partitionsList: partitionsList,
+	}, nil
+}
+
+// Collect collects metrics for BIG-IP LTM policies.
+func (c *LTMPolicyCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	err := c.collectVirtualServers(ch)
+	if err != nil {
+		logger.Warningf("Failed to get policies of virtual servers (%s)", err)
+	}
+	if policyErr := c.collectPolicies(ch); policyErr != nil {
+		logger.Warningf("Failed to get statistics for ltm policies (%s)", policyErr)
+		err = policyErr
+	}
+	if err != nil {
+		c.collectorScrapeStatus.WithLabelValues("ltm_policy").Set(float64(0))
+	} else {
+		c.collectorScrapeStatus.WithLabelValues("ltm_policy").Set(float64(1))
+		logger.Debugf("Successfully fetched statistics for ltm policies")
+	}
+
+	elapsed := time.Since(start)
+	c.collectorScrapeDuration.WithLabelValues("ltm_policy").Observe(float64(elapsed.Seconds()))
+	c.collectorScrapeStatus.Collect(ch)
+	c.collectorScrapeDuration.Collect(ch)
+	logger.Debugf("Getting ltm policy statistics took %s", elapsed)
+}
+
+// collectVirtualServers exports which policies are attached to which virtual
+// server.
+func (c *LTMPolicyCollector) collectVirtualServers(ch chan<- prometheus.Metric) error {
+	virtualServers, err := c.client.virtualServers()
+	if err != nil {
+		return err
+	}
+	for _, vs := range virtualServers {
+		if c.partitionsList != nil && !stringInSlice(vs.Partition, c.partitionsList) {
+			continue
+		}
+		for _, policy := range vs.PoliciesReference.Items {
+			ch <- prometheus.MustNewConstMetric(c.policyVSDesc, prometheus.GaugeValue, 1, policy.Partition, policy.Name, vs.Partition, vs.Name)
+		}
+	}
+	return nil
+}
+
+// collectPolicies exports how often each rule of each published policy
+// matched on each virtual server. The statistics count invocations per
+// action, and every action of a rule is invoked when the rule matches, so
+// the most invoked action of a rule gives its matches.
+func (c *LTMPolicyCollector) collectPolicies(ch chan<- prometheus.Metric) error {
+	var policies ltmPoliciesResponse
+	if err := c.client.get("/mgmt/tm/ltm/policy", &policies); err != nil {
+		return err
+	}
+	for _, policy := range policies.Items {
+		if strings.Contains(policy.FullPath, "/Drafts/") {
+			continue
+		}
+		if c.partitionsList != nil && !stringInSlice(policy.Partition, c.partitionsList) {
+			continue
+		}
+		var actionStats statsResponse
+		path := "/mgmt/tm/ltm/policy/" + strings.Replace(policy.FullPath, "/", "~", -1) + "/stats"
+		if err := c.client.get(path, &actionStats); err != nil {
+			return err
+		}
+		matches := make(map[policyRuleKey]float64)
+		for _, action := range actionStats.Entries {
+			entries := action.NestedStats.Entries
+			key := policyRuleKey{entries.description("vsName"), entries.description("policyRuleName")}
+			if invoked := entries.value("invoked"); invoked >= matches[key] {
+				matches[key] = invoked
+			}
+		}
+		for key, count := range matches {
+			vsPartition, vsName := splitFullPath(key.vs)
+			ch <- prometheus.MustNewConstMetric(c.ruleMatchesDesc, prometheus.CounterValue, count, policy.Partition, policy.Name, key.rule, vsPartition, vsName)
+		}
+	}
+	return nil
+}
+
+// Describe describes the metrics exported from this collector.
+func (c *LTMPolicyCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.ruleMatchesDesc
+	ch <- c.policyVSDesc
+	c.collectorScrapeStatus.Describe(ch)
+	c.collectorScrapeDuration.Describe(ch)
+}
@@ -0,0 +1,260 @@
+package collector
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// monitorTypeWorkers is the number of monitor types fetched at a time.
+const monitorTypeWorkers = 4
+
+// A MonitorCollector implements the prometheus.Collector.
+type MonitorCollector struct {
+	memberStatusDesc        *prometheus.Desc
+	memberInfoDesc          *prometheus.Desc
+	monitorInfoDesc         *prometheus.Desc
+	client                  *Client
+	partitionsList          []string
+	collectorScrapeStatus   *prometheus.GaugeVec
+	collectorScrapeDuration *prometheus.SummaryVec
+}
+
+// monitorTypesResponse is the body of /mgmt/tm/ltm/monitor, which links to
+// one collection per monitor type.
+type monitorTypesResponse struct {
+	Items []struct {
+		Reference struct {
+			Link string `json:"link"`
+		} `json:"reference"`
+	} `json:"items"`
+}
+
+type monitorsResponse struct {
+	Items []struct {
+		Name      string `json:"name"`
+		Partition string `json:"partition"`
+		FullPath  string `json:"fullPath"`
+		Interval  int    `json:"interval"`
+		Timeout   int    `json:"timeout"`
+	} `json:"items"`
+}
+
+// NewMonitorCollector returns a collector that collecting health monitor definitions and pool member monitor status
+func NewMonitorCollector(client *Client, namespace string, partitionsList []string) (*MonitorCollector, error) {
+	subsystem := "monitor"
+	return &MonitorCollector{
+		memberStatusDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "member_status"),
+			"member_status",
+			[]string{"partition", "pool", "member", "monitor_partition", "monitor"},
+			nil,
+		),
+		memberInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "member_info"),
+			"member_info",
+			[]string{"partition", "pool", "member", "monitor_rule", "monitor_status"},
+			nil,
+		),
+		monitorInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "info"),
+			"info",
+			[]string{"partition", "monitor", "type", "interval", "timeout"},
+			nil,
+		),
+		collectorScrapeStatus: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_status",
+				Help:      "collector_scrape_status",
+			},
+			[]string{"collector"},
+		),
+		collectorScrapeDuration: prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_duration",
+				Help:      "collector_scrape_duration",
+			},
+			[]string{"collector"},
+		),
+		client:         client,
+		partitionsList: partitionsList,
+	}, nil
+}
+
+// Collect collects metrics for BIG-IP health monitors.
+func (c *MonitorCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	memberErr := c.collectMembers(ch)
+	if memberErr != nil {
+		logger.Warningf("Failed to get monitor status of pool members (%s)", memberErr)
+	}
+	monitorErr := c.collectMonitors(ch)
+	if monitorErr != nil {
+		logger.Warningf("Failed to get monitors (%s)", monitorErr)
+	}
+	if memberErr != nil || monitorErr != nil {
+		c.collectorScrapeStatus.WithLabelValues("monitor").Set(float64(0))
+	} else {
+		c.collectorScrapeStatus.WithLabelValues("monitor").Set(float64(1))
+		logger.Debugf("Successfully fetched monitors")
+	}
+
+	elapsed := time.Since(start)
+	c.collectorScrapeDuration.WithLabelValues("monitor").Observe(float64(elapsed.Seconds()))
+	c.collectorScrapeStatus.Collect(ch)
+	c.collectorScrapeDuration.Collect(ch)
+	logger.Debugf("Getting monitors took %s", elapsed)
+}
+
+// collectMembers exports the monitor status of every pool member. BIG-IP
+// only reports the status of the member as a whole, so a monitor is
+// considered down when the member is down and its status reason names the
+// monitor, or names no monitor of the member at all.
+func (c *MonitorCollector) collectMembers(ch chan<- prometheus.Metric) error {
+	var allPoolStats statsResponse
+	if err := c.client.get("/mgmt/tm/ltm/pool/stats?expandSubcollections=true", &allPoolStats); err != nil {
+		return err
+	}
+	for _, poolStats := range allPoolStats.Entries {
+		poolEntries := poolStats.NestedStats.Entries
+		partition, poolName := splitFullPath(poolEntries.description("tmName"))
+
+		if c.partitionsList != nil && !stringInSlice(partition, c.partitionsList) {
+			continue
+		}
+
+		for key, members := range poolEntries {
+			if !strings.HasSuffix(key, "/members/stats") {
+				continue
+			}
+			for _, memberStats := range members.NestedStats.Entries {
+				entries := memberStats.NestedStats.Entries
+				_, nodeName := splitFullPath(entries.description("nodeName"))
+				member := joinHostPort(nodeName, formatPort(entries.value("port")))
+				monitorRule := entries.description("monitorRule")
+				monitorStatus := entries.description("monitorStatus")
+				statusReason := entries.description("status.statusReason")
+
+				ch <- prometheus.MustNewConstMetric(c.memberInfoDesc, prometheus.GaugeValue, 1,
+					partition, poolName, member, monitorRule, monitorStatus)
+
+				monitors := monitorsInRule(monitorRule)
+				named := false
+				for _, monitor := range monitors {
+					if namesMonitor(statusReason, monitor) {
+						named = true
+					}
+				}
+				for _, monitor := range monitors {
+					up := monitorStatus == "up" || (named && !namesMonitor(statusReason, monitor))
+					monitorPartition, monitorName := splitFullPath(monitor)
+					ch <- prometheus.MustNewConstMetric(c.memberStatusDesc, prometheus.GaugeValue, boolToFloat64(up),
+						partition, poolName, member, monitorPartition, monitorName)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// monitorsInRule returns the monitors of a monitor rule like
+// "min 1 of /Common/http /Common/tcp (pool monitor)".
+func monitorsInRule(rule string) []string {
+	var monitors []string
+	for _, field := range strings.Fields(rule) {
+		if strings.HasPrefix(field, "/") {
+			monitors = append(monitors, field)
+		}
+	}
+	return monitors
+}
+
+// namesMonitor reports whether the status reason names monitor as a whole
+// word, so that e.g. /Common/tcp is not matched by /Common/tcp_half_open.
+func namesMonitor(reason, monitor string) bool {
+	for i := 0; ; {
+		j := strings.Index(reason[i:], monitor)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(monitor)
+		if (start == 0 || isMonitorBoundary(reason[start-1])) && (end == len(reason) || isMonitorBoundary(reason[end])) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+func isMonitorBoundary(b byte) bool {
+	return strings.IndexByte(": \t\r\n()[],;", b) >= 0
+}
+
+// collectMonitors exports the definition of every monitor. Monitors are
+// listed per type, so the types are fetched concurrently by at most
+// monitorTypeWorkers requests at a time.
+func (c *MonitorCollector) collectMonitors(ch chan<- prometheus.Metric) error {
+	var monitorTypes monitorTypesResponse
+	if err := c.client.get("/mgmt/tm/ltm/monitor", &monitorTypes); err != nil {
+		return err
+	}
+
+	paths := make([]string, 0, len(monitorTypes.Items))
+	for _, item := range monitorTypes.Items {
+		link, err := url.Parse(item.Reference.Link)
+		if err != nil {
+			return fmt.Errorf("invalid monitor type link %q: %s", item.Reference.Link, err)
+		}
+		paths = append(paths, link.Path)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		workers  = make(chan struct{}, monitorTypeWorkers)
+	)
+	for _, path := range paths {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(path string) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+			monitorType := path[strings.LastIndex(path, "/")+1:]
+			var monitors monitorsResponse
+			if err := c.client.get(path, &monitors); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				return
+			}
+			for _, monitor := range monitors.Items {
+				if c.partitionsList != nil && !stringInSlice(monitor.Partition, c.partitionsList) {
+					continue
+				}
+				ch <- prometheus.MustNewConstMetric(c.monitorInfoDesc, prometheus.GaugeValue, 1,
+					monitor.Partition, monitor.Name, monitorType, fmt.Sprint(monitor.Interval), fmt.Sprint(monitor.Timeout))
+			}
+		}(path)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// Describe describes the metrics exported from this collector.
+func (c *MonitorCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.memberStatusDesc
+	ch <- c.memberInfoDesc
+	ch <- c.monitorInfoDesc
+	c.collectorScrapeStatus.Describe(ch)
+	c.collectorScrapeDuration.Describe(ch)
+}
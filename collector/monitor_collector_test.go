@@ -0,0 +1,49 @@
+package collector
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMonitorsInRule(t *testing.T) {
+	tests := []struct {
+		rule     string
+		monitors []string
+	}{
+		{"/Common/http (pool monitor)", []string{"/Common/http"}},
+		{"/Common/http and /Common/tcp_half_open (pool monitor)", []string{"/Common/http", "/Common/tcp_half_open"}},
+		{"min 1 of /Common/http /Common/tcp (pool monitor)", []string{"/Common/http", "/Common/tcp"}},
+		{"/app/app.app/https_check (node monitor)", []string{"/app/app.app/https_check"}},
+		{"none", nil},
+		{"", nil},
+	}
+	for _, test := range tests {
+		if monitors := monitorsInRule(test.rule); !reflect.DeepEqual(monitors, test.monitors) {
+			t.Errorf("%q: got %q, want %q", test.rule, monitors, test.monitors)
+		}
+	}
+}
+
+func TestNamesMonitor(t *testing.T) {
+	tests := []struct {
+		reason  string
+		monitor string
+		names   bool
+	}{
+		{"Pool member has been marked down by a monitor", "/Common/tcp", false},
+		{"/Common/tcp: No successful responses received before deadline. @2019/09/27 14:47:51. ", "/Common/tcp", true},
+		{"/Common/tcp_half_open: No successful responses received before deadline.", "/Common/tcp", false},
+		{"/Common/tcp_half_open: No successful responses received before deadline.", "/Common/tcp_half_open", true},
+		{"/Common/tcp_half_open: down, /Common/tcp: down", "/Common/tcp", true},
+		{"Monitor /Common/tcp", "/Common/tcp", true},
+		{"[ /Common/http: up, /Common/tcp: down ]", "/Common/tcp", true},
+		{"/Common/tcp2: down", "/Common/tcp", false},
+		{"/Common/http_head_f5 down", "/Common/http", false},
+		{"", "/Common/tcp", false},
+	}
+	for _, test := range tests {
+		if names := namesMonitor(test.reason, test.monitor); names != test.names {
+			t.Errorf("%q in %q: got %t, want %t", test.monitor, test.reason, names, test.names)
+		}
+	}
+}
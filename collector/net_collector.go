@@ -0,0 +1,215 @@
+package collector
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// A NetCollector implements the prometheus.Collector.
+type NetCollector struct {
+	currentConnectionsDesc  *prometheus.Desc
+	totalConnectionsDesc    *prometheus.Desc
+	routesDesc              *prometheus.Desc
+	arpEntriesDesc          *prometheus.Desc
+	ndpEntriesDesc          *prometheus.Desc
+	selfIPInfoDesc          *prometheus.Desc
+	client                  *Client
+	partitionsList          []string
+	collectorScrapeStatus   *prometheus.GaugeVec
+	collectorScrapeDuration *prometheus.SummaryVec
+}
+
+type routeDomainsResponse struct {
+	Items []struct {
+		Name      string `json:"name"`
+		Partition string `json:"partition"`
+		FullPath  string `json:"fullPath"`
+		ID        int    `json:"id"`
+	} `json:"items"`
+}
+
+type selfIPsResponse struct {
+	Items []struct {
+		Name         string `json:"name"`
+		Partition    string `json:"partition"`
+		Address      string `json:"address"`
+		Vlan         string `json:"vlan"`
+		TrafficGroup string `json:"trafficGroup"`
+		Floating     string `json:"floating"`
+	} `json:"items"`
+}
+
+// NewNetCollector returns a collector that collecting route domain, route, ARP and self IP statistics
+func NewNetCollector(client *Client, namespace string, partitionsList []string) (*NetCollector, error) {
+	subsystem := "net"
+	return &NetCollector{
+		currentConnectionsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "route_domain_current_connections"),
+			"route_domain_current_connections",
+			[]string{"partition", "route_domain"},
+			nil,
+		),
+		totalConnectionsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "route_domain_connections_total"),
+			"route_domain_connections_total",
+			[]string{"partition", "route_domain"},
+			nil,
+		),
+		routesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "routes"),
+			"routes",
+			[]string{"route_domain", "type"},
+			nil,
+		),
+		arpEntriesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "arp_entries"),
+			"arp_entries",
+			[]string{"route_domain", "status"},
+			nil,
+		),
+		ndpEntriesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "ndp_entries"),
+			"ndp_entries",
+			[]string{"route_domain", "status"},
+			nil,
+		),
+		selfIPInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "self_ip_info"),
+			"self_ip_info",
+			[]string{"partition", "self_ip", "address", "vlan", "traffic_group", "floating", "route_domain"},
+			nil,
+		),
+		collectorScrapeStatus: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_status",
+				Help:      "collector_scrape_status",
+			},
+			[]string{"collector"},
+		),
+		collectorScrapeDuration: prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_duration",
+				Help:      "collector_scrape_duration",
+			},
+			[]string{"collector"},
+		),
+		client:         client,
+		partitionsList: partitionsList,
+	}, nil
+}
+
+// Collect collects metrics for BIG-IP routing. All metrics are labelled
+// with the route domain id. Routes and ARP entries have no partition, so
+// with a partition filter only those in the route domains of the
+// partitions are counted.
+func (c *NetCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	err := c.collect(ch)
+	if err != nil {
+		c.collectorScrapeStatus.WithLabelValues("net").Set(float64(0))
+		logger.Warningf("Failed to get routing (%s)", err)
+	} else {
+		c.collectorScrapeStatus.WithLabelValues("net").Set(float64(1))
+		logger.Debugf("Successfully fetched routing")
+	}
+
+	elapsed := time.Since(start)
+	c.collectorScrapeDuration.WithLabelValues("net").Observe(float64(elapsed.Seconds()))
+	c.collectorScrapeStatus.Collect(ch)
+	c.collectorScrapeDuration.Collect(ch)
+	logger.Debugf("Getting routing took %s", elapsed)
+}
+
+func (c *NetCollector) collect(ch chan<- prometheus.Metric) error {
+	var routeDomains routeDomainsResponse
+	if err := c.client.get("/mgmt/tm/net/route-domain", &routeDomains); err != nil {
+		return err
+	}
+	ids := make(map[string]string)
+	allowed := make(map[string]bool)
+	for _, rd := range routeDomains.Items {
+		id := fmt.Sprint(rd.ID)
+		ids[rd.FullPath] = id
+		if c.partitionsList == nil || stringInSlice(rd.Partition, c.partitionsList) {
+			allowed[id] = true
+		}
+	}
+
+	var rdStats statsResponse
+	if err := c.client.get("/mgmt/tm/net/route-domain/stats", &rdStats); err != nil {
+		return err
+	}
+	for _, rd := range rdStats.Entries {
+		entries := rd.NestedStats.Entries
+		fullPath := entries.description("tmName")
+		partition, _ := splitFullPath(fullPath)
+		id := ids[fullPath]
+		if !allowed[id] {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.currentConnectionsDesc, prometheus.GaugeValue, entries.value("clientside.curConns"), partition, id)
+		ch <- prometheus.MustNewConstMetric(c.totalConnectionsDesc, prometheus.CounterValue, entries.value("clientside.totConns"), partition, id)
+	}
+
+	// The route table holds static as well as dynamic routes.
+	if err := c.countEntries(ch, "/mgmt/tm/net/route/stats", "destination", "type", c.routesDesc, allowed); err != nil {
+		return err
+	}
+	if err := c.countEntries(ch, "/mgmt/tm/net/arp/stats", "ipAddress", "status", c.arpEntriesDesc, allowed); err != nil {
+		return err
+	}
+	if err := c.countEntries(ch, "/mgmt/tm/net/ndp/stats", "ipAddress", "status", c.ndpEntriesDesc, allowed); err != nil {
+		return err
+	}
+
+	var selfIPs selfIPsResponse
+	if err := c.client.get("/mgmt/tm/net/self", &selfIPs); err != nil {
+		return err
+	}
+	for _, self := range selfIPs.Items {
+		if c.partitionsList != nil && !stringInSlice(self.Partition, c.partitionsList) {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.selfIPInfoDesc, prometheus.GaugeValue, 1,
+			self.Partition, self.Name, self.Address, self.Vlan, self.TrafficGroup, self.Floating, routeDomainOf(self.Address))
+	}
+	return nil
+}
+
+// countEntries exports the number of entries of a stats endpoint per route
+// domain, taken from addressStat, and per the value of labelStat.
+func (c *NetCollector) countEntries(ch chan<- prometheus.Metric, path, addressStat, labelStat string, desc *prometheus.Desc, allowed map[string]bool) error {
+	var stats statsResponse
+	if err := c.client.get(path, &stats); err != nil {
+		return err
+	}
+	counts := make(map[[2]string]int)
+	for _, entry := range stats.Entries {
+		entries := entry.NestedStats.Entries
+		id := routeDomainOf(entries.description(addressStat))
+		if !allowed[id] {
+			continue
+		}
+		counts[[2]string{id, entries.description(labelStat)}]++
+	}
+	for key, count := range counts {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(count), key[0], key[1])
+	}
+	return nil
+}
+
+// Describe describes the metrics exported from this collector.
+func (c *NetCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.currentConnectionsDesc
+	ch <- c.totalConnectionsDesc
+	ch <- c.routesDesc
+	ch <- c.arpEntriesDesc
+	ch <- c.ndpEntriesDesc
+	ch <- c.selfIPInfoDesc
+	c.collectorScrapeStatus.Describe(ch)
+	c.collectorScrapeDuration.Describe(ch)
+}
@@ -0,0 +1,242 @@
+package collector
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// A PersistenceCollector implements the prometheus.Collector. It summarises
+// the persistence records and the connection table, which can hold millions
+// of rows on a busy device, so it is disabled by default and reads at most
+// maxRows rows of each.
+type PersistenceCollector struct {
+	persistenceRecordsDesc   *prometheus.Desc
+	persistenceMemberDesc    *prometheus.Desc
+	persistenceRowsDesc      *prometheus.Desc
+	persistenceTruncatedDesc *prometheus.Desc
+	connectionVSDesc         *prometheus.Desc
+	connectionMemberDesc     *prometheus.Desc
+	connectionRowsDesc       *prometheus.Desc
+	connectionTruncatedDesc  *prometheus.Desc
+	client                   *Client
+	partitionsList           []string
+	maxRows                  int
+	collectorScrapeStatus    *prometheus.GaugeVec
+	collectorScrapeDuration  *prometheus.SummaryVec
+}
+
+type persistenceKey struct {
+	partition, vs, pool, mode string
+}
+
+type memberKey struct {
+	pool, member string
+}
+
+// NewPersistenceCollector returns a collector that summarising persistence records and connections
+func NewPersistenceCollector(client *Client, namespace string, partitionsList []string, maxRows int) (*PersistenceCollector, error) {
+	return &PersistenceCollector{
+		persistenceRecordsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "persistence", "records"),
+			"records",
+			[]string{"partition", "vs", "pool", "mode"},
+			nil,
+		),
+		persistenceMemberDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "persistence", "member_records"),
+			"member_records",
+			[]string{"pool", "member"},
+			nil,
+		),
+		persistenceRowsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "persistence", "rows_read"),
+			"rows_read",
+			nil,
+			nil,
+		),
+		persistenceTruncatedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "persistence", "truncated"),
+			"truncated",
+			nil,
+			nil,
+		),
+		connectionVSDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "connection_table", "vs_connections"),
+			"vs_connections",
+			[]string{"partition", "vs", "destination"},
+			nil,
+		),
+		connectionMemberDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "connection_table", "member_connections"),
+			"member_connections",
+			[]string{"member"},
+			nil,
+		),
+		connectionRowsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "connection_table", "rows_read"),
+			"rows_read",
+			nil,
+			nil,
+		),
+		connectionTruncatedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "connection_table", "truncated"),
+			"truncated",
+			nil,
+			nil,
+		),
+		collectorScrapeStatus: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_status",
+				Help:      "collector_scrape_status",
+			},
+			[]string{"collector"},
+		),
+		collectorScrapeDuration: prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_duration",
+				Help:      "collector_scrape_duration",
+			},
+			[]string{"collector"},
+		),
+		client:         client,
+		partitionsList: partitionsList,
+		maxRows:        maxRows,
+	}, nil
+}
+
+// Collect collects metrics for BIG-IP persistence records and connections.
+func (c *PersistenceCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	virtualServers, err := c.client.virtualServers()
+	if err == nil {
+		destinations := destinationsByAddress(virtualServers)
+		if err = c.collectPersistenceRecords(ch, destinations); err != nil {
+			logger.Warningf("Failed to get persistence records (%s)", err)
+		}
+		if connErr := c.collectConnections(ch, destinations); connErr != nil {
+			logger.Warningf("Failed to get connection table (%s)", connErr)
+			err = connErr
+		}
+	} else {
+		logger.Warningf("Failed to get virtual servers (%s)", err)
+	}
+	if err != nil {
+		c.collectorScrapeStatus.WithLabelValues("persistence").Set(float64(0))
+	} else {
+		c.collectorScrapeStatus.WithLabelValues("persistence").Set(float64(1))
+		logger.Debugf("Successfully fetched persistence records and connections")
+	}
+
+	elapsed := time.Since(start)
+	c.collectorScrapeDuration.WithLabelValues("persistence").Observe(float64(elapsed.Seconds()))
+	c.collectorScrapeStatus.Collect(ch)
+	c.collectorScrapeDuration.Collect(ch)
+	logger.Debugf("Getting persistence records and connections took %s", elapsed)
+}
+
+func (c *PersistenceCollector) collectPersistenceRecords(ch chan<- prometheus.Metric, destinations map[string]virtualServer) error {
+	records := make(map[persistenceKey]int)
+	members := make(map[memberKey]int)
+	rows := 0
+	truncated, err := c.client.getStatsEntries("/mgmt/tm/ltm/persistence/persist-records", c.maxRows, func(entries statsEntries) {
+		rows++
+		pool := entries.description("poolName")
+		vs := destinations[stripRouteDomain(joinHostPort(entries.description("virtualAddr"), formatPort(entries.value("virtualPort"))))]
+		partition := vs.Partition
+		if partition == "" {
+			partition, _ = splitFullPath(pool)
+		}
+		if c.partitionsList != nil && !stringInSlice(partition, c.partitionsList) {
+			return
+		}
+		records[persistenceKey{partition, vs.Name, pool, entries.description("mode")}]++
+		member := stripRouteDomain(joinHostPort(entries.description("nodeAddr"), formatPort(entries.value("nodePort"))))
+		members[memberKey{pool, member}]++
+	})
+	if err != nil {
+		return err
+	}
+	if truncated {
+		logger.Warningf("Read only the first %d persistence records", c.maxRows)
+	}
+
+	for key, count := range records {
+		ch <- prometheus.MustNewConstMetric(c.persistenceRecordsDesc, prometheus.GaugeValue, float64(count), key.partition, key.vs, key.pool, key.mode)
+	}
+	for key, count := range members {
+		ch <- prometheus.MustNewConstMetric(c.persistenceMemberDesc, prometheus.GaugeValue, float64(count), key.pool, key.member)
+	}
+	ch <- prometheus.MustNewConstMetric(c.persistenceRowsDesc, prometheus.GaugeValue, float64(rows))
+	ch <- prometheus.MustNewConstMetric(c.persistenceTruncatedDesc, prometheus.GaugeValue, boolToFloat64(truncated))
+	return nil
+}
+
+// collectConnections counts the rows of the connection table per virtual
+// server and per pool member. Each row lists the client side client and
+// server followed by the server side client and server, e.g.
+//
+//	10.0.0.1:51234  10.0.0.2:80  10.0.0.1:51234  10.1.1.1:80  tcp  3  (tmm: 1)  none
+func (c *PersistenceCollector) collectConnections(ch chan<- prometheus.Metric, destinations map[string]virtualServer) error {
+	vsConnections := make(map[string]int)
+	memberConnections := make(map[string]int)
+	rows := 0
+	truncated := false
+	err := c.client.getRawLines("/mgmt/tm/sys/connection", func(line string) bool {
+		fields := strings.Fields(line)
+		if len(fields) < 5 || strings.HasPrefix(line, "Total records") {
+			return true
+		}
+		if rows == c.maxRows {
+			truncated = true
+			return false
+		}
+		rows++
+		vsConnections[stripRouteDomain(fields[1])]++
+		if fields[3] != "any6.any" {
+			memberConnections[stripRouteDomain(fields[3])]++
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if truncated {
+		logger.Warningf("Read only the first %d connections", c.maxRows)
+	}
+
+	for destination, count := range vsConnections {
+		vs := destinations[destination]
+		if c.partitionsList != nil && !stringInSlice(vs.Partition, c.partitionsList) {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.connectionVSDesc, prometheus.GaugeValue, float64(count), vs.Partition, vs.Name, destination)
+	}
+	// The connection table does not tell which partition a pool member
+	// belongs to, so members are only exported without a partition filter.
+	if c.partitionsList == nil {
+		for member, count := range memberConnections {
+			ch <- prometheus.MustNewConstMetric(c.connectionMemberDesc, prometheus.GaugeValue, float64(count), member)
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(c.connectionRowsDesc, prometheus.GaugeValue, float64(rows))
+	ch <- prometheus.MustNewConstMetric(c.connectionTruncatedDesc, prometheus.GaugeValue, boolToFloat64(truncated))
+	return nil
+}
+
+// Describe describes the metrics exported from this collector.
+func (c *PersistenceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.persistenceRecordsDesc
+	ch <- c.persistenceMemberDesc
+	ch <- c.persistenceRowsDesc
+	ch <- c.persistenceTruncatedDesc
+	ch <- c.connectionVSDesc
+	ch <- c.connectionMemberDesc
+	ch <- c.connectionRowsDesc
+	ch <- c.connectionTruncatedDesc
+	c.collectorScrapeStatus.Describe(ch)
+	c.collectorScrapeDuration.Describe(ch)
+}
@@ -0,0 +1,56 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestCollectConnections(t *testing.T) {
+	body := `{"kind":"tm:sys:connection:connectionstats","apiRawValues":{"apiAnonymous":"` +
+		`10.0.0.1:51234  10.0.0.2:80  10.0.0.1:51234  10.1.1.1:80  tcp  3  (tmm: 1)  none\n` +
+		`10.0.0.3:51234  10.0.0.2:80  10.0.0.3:51234  10.1.1.2:80  tcp  5  (tmm: 0)  none\n` +
+		`10.0.0.4:51234  10.0.0.2:80  10.0.0.4:51234  10.1.1.1:80  tcp  1  (tmm: 1)  none\n` +
+		`Total records returned: 3\n"}}`
+
+	tests := []struct {
+		name      string
+		maxRows   int
+		rows      float64
+		truncated float64
+	}{
+		{name: "all rows", maxRows: 10, rows: 3},
+		{name: "exactly max rows", maxRows: 3, rows: 3},
+		{name: "cut off", maxRows: 2, rows: 2, truncated: 1},
+	}
+	for _, test := range tests {
+		client, done := newTestClient(t, body)
+		c, _ := NewPersistenceCollector(client, "bigip", nil, test.maxRows)
+		ch := make(chan prometheus.Metric, 100)
+		err := c.collectConnections(ch, map[string]virtualServer{})
+		done()
+		close(ch)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+		values := make(map[*prometheus.Desc]float64)
+		for m := range ch {
+			var metric dto.Metric
+			if err := m.Write(&metric); err != nil {
+				t.Fatal(err)
+			}
+			values[m.Desc()] += metric.GetGauge().GetValue()
+		}
+		if got := values[c.connectionRowsDesc]; got != test.rows {
+			t.Errorf("%s: got %v rows, want %v", test.name, got, test.rows)
+		}
+		if got := values[c.connectionTruncatedDesc]; got != test.truncated {
+			t.Errorf("%s: got truncated %v, want %v", test.name, got, test.truncated)
+		}
+		if got := values[c.connectionVSDesc]; got != test.rows {
+			t.Errorf("%s: got %v virtual server connections, want %v", test.name, got, test.rows)
+		}
+	}
+}
@@ -11,7 +11,10 @@ import (
 // A RuleCollector implements the prometheus.Collector.
 type RuleCollector struct {
 	metrics                   map[string]ruleMetric
+	secondsMetrics            map[string]ruleMetric
+	vsDesc                    *prometheus.Desc
 	bigip                     *f5.Device
+	client                    *Client
 	partitionsList           []string
 	collectorScrapeStatus   *prometheus.GaugeVec
 	collectorScrapeDuration *prometheus.SummaryVec
@@ -24,7 +27,7 @@ type ruleMetric struct {
 }
 
 // NewRuleCollector returns a collector that collecting iRule statistics
-func NewRuleCollector(bigip *f5.Device, namespace string, partitionsList []string) (*RuleCollector, error) {
+func NewRuleCollector(bigip *f5.Device, client *Client, namespace string, partitionsList []string) (*RuleCollector, error) {
 	var (
 		subsystem  = "rule"
 		labelNames = []string{"partition", "rule", "event"}
@@ -101,7 +104,7 @@ func NewRuleCollector(bigip *f5.Device, namespace string, partitionsList []strin
 				extract: func(entries f5.LBRuleStatsInnerEntries) float64 {
 					return float64(entries.MaxCycles.Value)
 				},
-				valueType: prometheus.CounterValue,
+				valueType: prometheus.GaugeValue,
 			},
 			"avgCycles": {
 				desc: prometheus.NewDesc(
@@ -116,6 +119,63 @@ func NewRuleCollector(bigip *f5.Device, namespace string, partitionsList []strin
 				valueType: prometheus.GaugeValue,
 			},
 		},
+		// The cycle counts divided by the CPU clock speed in Hz.
+		secondsMetrics: map[string]ruleMetric{
+			"minCycles": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "min_seconds"),
+					"min_seconds",
+					labelNames,
+					nil,
+				),
+				extract: func(entries f5.LBRuleStatsInnerEntries) float64 {
+					return float64(entries.MinCycles.Value)
+				},
+				valueType: prometheus.GaugeValue,
+			},
+			"maxCycles": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "max_seconds"),
+					"max_seconds",
+					labelNames,
+					nil,
+				),
+				extract: func(entries f5.LBRuleStatsInnerEntries) float64 {
+					return float64(entries.MaxCycles.Value)
+				},
+				valueType: prometheus.GaugeValue,
+			},
+			"avgCycles": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "avg_seconds"),
+					"avg_seconds",
+					labelNames,
+					nil,
+				),
+				extract: func(entries f5.LBRuleStatsInnerEntries) float64 {
+					return float64(entries.AvgCycles.Value)
+				},
+				valueType: prometheus.GaugeValue,
+			},
+			"cpuSeconds": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "cpu_seconds_total"),
+					"cpu_seconds_total",
+					labelNames,
+					nil,
+				),
+				extract: func(entries f5.LBRuleStatsInnerEntries) float64 {
+					return float64(entries.TotalExecutions.Value) * float64(entries.AvgCycles.Value)
+				},
+				valueType: prometheus.CounterValue,
+			},
+		},
+		vsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "vs_info"),
+			"vs_info",
+			[]string{"partition", "rule", "vs_partition", "vs"},
+			nil,
+		),
 		collectorScrapeStatus: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
@@ -133,6 +193,7 @@ func NewRuleCollector(bigip *f5.Device, namespace string, partitionsList []strin
 			[]string{"collector"},
 		),
 		bigip:           bigip,
+		client:          client,
 		partitionsList: partitionsList,
 	}, nil
 }
@@ -140,6 +201,12 @@ func NewRuleCollector(bigip *f5.Device, namespace string, partitionsList []strin
 // Collect collects metrics for BIG-IP iRules.
 func (c *RuleCollector) Collect(ch chan<- prometheus.Metric) {
 	start := time.Now()
+	// Without the CPU clock speed only the cycle counts are exported, the
+	// scrape status only reflects the rule statistics.
+	mhz, mhzErr := c.client.cpuMHz()
+	if mhzErr != nil {
+		logger.Warningf("Failed to get CPU clock speed, exporting cycle counts only (%s)", mhzErr)
+	}
 	err, allRuleStats := c.bigip.ShowAllRuleStats()
 	if err != nil {
 		c.collectorScrapeStatus.WithLabelValues("rule").Set(float64(0))
@@ -162,9 +229,19 @@ func (c *RuleCollector) Collect(ch chan<- prometheus.Metric) {
 			for _, metric := range c.metrics {
 				ch <- prometheus.MustNewConstMetric(metric.desc, metric.valueType, metric.extract(ruleStats.NestedStats.Entries), labels...)
 			}
+			if mhzErr == nil {
+				for _, metric := range c.secondsMetrics {
+					ch <- prometheus.MustNewConstMetric(metric.desc, metric.valueType, metric.extract(ruleStats.NestedStats.Entries)/(mhz*1e6), labels...)
+				}
+			}
+		}
+		if vsErr := c.collectVirtualServers(ch); vsErr != nil {
+			c.collectorScrapeStatus.WithLabelValues("rule").Set(float64(0))
+			logger.Warningf("Failed to get iRules of virtual servers (%s)", vsErr)
+		} else {
+			c.collectorScrapeStatus.WithLabelValues("rule").Set(float64(1))
+			logger.Debugf("Successfully fetched statistics for rules")
 		}
-		c.collectorScrapeStatus.WithLabelValues("rule").Set(float64(1))
-		logger.Debugf("Successfully fetched statistics for rules")
 	}
 
 	elapsed := time.Since(start)
@@ -174,11 +251,34 @@ func (c *RuleCollector) Collect(ch chan<- prometheus.Metric) {
 	logger.Debugf("Getting rule stats took %s", elapsed)
 }
 
+// collectVirtualServers exports which iRules are attached to which virtual
+// server.
+func (c *RuleCollector) collectVirtualServers(ch chan<- prometheus.Metric) error {
+	virtualServers, err := c.client.virtualServers()
+	if err != nil {
+		return err
+	}
+	for _, vs := range virtualServers {
+		if c.partitionsList != nil && !stringInSlice(vs.Partition, c.partitionsList) {
+			continue
+		}
+		for _, rule := range vs.Rules {
+			rulePartition, ruleName := splitFullPath(rule)
+			ch <- prometheus.MustNewConstMetric(c.vsDesc, prometheus.GaugeValue, 1, rulePartition, ruleName, vs.Partition, vs.Name)
+		}
+	}
+	return nil
+}
+
 // Describe describes the metrics exported from this collector.
 func (c *RuleCollector) Describe(ch chan<- *prometheus.Desc) {
 	for _, metric := range c.metrics {
 		ch <- metric.desc
 	}
+	for _, metric := range c.secondsMetrics {
+		ch <- metric.desc
+	}
+	ch <- c.vsDesc
 	c.collectorScrapeStatus.Describe(ch)
 	c.collectorScrapeDuration.Describe(ch)
 }
@@ -0,0 +1,221 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// snatPortsPerAddress is the number of ephemeral ports (1024-65535) a
+// translation address can use towards a single destination. The port
+// utilization is estimated as current connections divided by it. Ports only
+// run out per destination, so the estimate is exact when all connections go
+// to the same destination and overstates the utilization otherwise.
+const snatPortsPerAddress = 64512
+
+// A SNATCollector implements the prometheus.Collector.
+type SNATCollector struct {
+	metrics                 map[string]statsMetric
+	client                  *Client
+	partitionsList          []string
+	collectorScrapeStatus   *prometheus.GaugeVec
+	collectorScrapeDuration *prometheus.SummaryVec
+}
+
+type snatPoolsResponse struct {
+	Items []struct {
+		Name      string   `json:"name"`
+		Partition string   `json:"partition"`
+		FullPath  string   `json:"fullPath"`
+		Members   []string `json:"members"`
+	} `json:"items"`
+}
+
+// NewSNATCollector returns a collector that collecting snat translation statistics
+func NewSNATCollector(client *Client, namespace string, partitionsList []string) (*SNATCollector, error) {
+	var (
+		subsystem  = "snat_translation"
+		labelNames = []string{"partition", "translation", "snatpool"}
+	)
+	return &SNATCollector{
+		metrics: map[string]statsMetric{
+			"serverside.bitsIn": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "serverside_bytes_in"),
+					"serverside_bytes_in",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("serverside.bitsIn") / 8
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"serverside.bitsOut": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "serverside_bytes_out"),
+					"serverside_bytes_out",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("serverside.bitsOut") / 8
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"serverside.pktsIn": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "serverside_pkts_in"),
+					"serverside_pkts_in",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("serverside.pktsIn")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"serverside.pktsOut": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "serverside_pkts_out"),
+					"serverside_pkts_out",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("serverside.pktsOut")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"serverside.curConns": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "serverside_cur_conns"),
+					"serverside_cur_conns",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("serverside.curConns")
+				},
+				valueType: prometheus.GaugeValue,
+			},
+			"serverside.maxConns": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "serverside_max_conns"),
+					"serverside_max_conns",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("serverside.maxConns")
+				},
+				valueType: prometheus.GaugeValue,
+			},
+			"serverside.totConns": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "serverside_tot_conns"),
+					"serverside_tot_conns",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("serverside.totConns")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"portUtilizationRatio": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "port_utilization_ratio"),
+					"port_utilization_ratio",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("serverside.curConns") / snatPortsPerAddress
+				},
+				valueType: prometheus.GaugeValue,
+			},
+		},
+		collectorScrapeStatus: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_status",
+				Help:      "collector_scrape_status",
+			},
+			[]string{"collector"},
+		),
+		collectorScrapeDuration: prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_duration",
+				Help:      "collector_scrape_duration",
+			},
+			[]string{"collector"},
+		),
+		client:         client,
+		partitionsList: partitionsList,
+	}, nil
+}
+
+// Collect collects metrics for BIG-IP snat translation addresses.
+func (c *SNATCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	var snatPools snatPoolsResponse
+	var allTranslationStats statsResponse
+	err := c.client.get("/mgmt/tm/ltm/snatpool", &snatPools)
+	if err == nil {
+		err = c.client.get("/mgmt/tm/ltm/snat-translation/stats", &allTranslationStats)
+	}
+	if err != nil {
+		c.collectorScrapeStatus.WithLabelValues("snat").Set(float64(0))
+		logger.Warningf("Failed to get statistics for snat translations (%s)", err)
+	} else {
+		// A translation address can be a member of several snat pools. The
+		// snat pool stats only repeat the stats of their member
+		// translations, so the pools are taken from the configuration.
+		poolsByTranslation := make(map[string][]string)
+		for _, pool := range snatPools.Items {
+			for _, member := range pool.Members {
+				poolsByTranslation[member] = append(poolsByTranslation[member], pool.FullPath)
+			}
+		}
+
+		for _, translationStats := range allTranslationStats.Entries {
+			entries := translationStats.NestedStats.Entries
+			fullPath := entries.description("tmName")
+			partition, translation := splitFullPath(fullPath)
+
+			if c.partitionsList != nil && !stringInSlice(partition, c.partitionsList) {
+				continue
+			}
+
+			pools := poolsByTranslation[fullPath]
+			if len(pools) == 0 {
+				pools = []string{""}
+			}
+			for _, pool := range pools {
+				labels := []string{partition, translation, pool}
+				for _, metric := range c.metrics {
+					ch <- prometheus.MustNewConstMetric(metric.desc, metric.valueType, metric.extract(entries), labels...)
+				}
+			}
+		}
+		c.collectorScrapeStatus.WithLabelValues("snat").Set(float64(1))
+		logger.Debugf("Successfully fetched statistics for snat translations")
+	}
+
+	elapsed := time.Since(start)
+	c.collectorScrapeDuration.WithLabelValues("snat").Observe(float64(elapsed.Seconds()))
+	c.collectorScrapeStatus.Collect(ch)
+	c.collectorScrapeDuration.Collect(ch)
+	logger.Debugf("Getting snat translation statistics took %s", elapsed)
+}
+
+// Describe describes the metrics exported from this collector.
+func (c *SNATCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, metric := range c.metrics {
+		ch <- metric.desc
+	}
+	c.collectorScrapeStatus.Describe(ch)
+	c.collectorScrapeDuration.Describe(ch)
+}
@@ -0,0 +1,180 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// A SoftwareCollector implements the prometheus.Collector.
+type SoftwareCollector struct {
+	versionInfoDesc         *prometheus.Desc
+	volumeInfoDesc          *prometheus.Desc
+	volumeActiveDesc        *prometheus.Desc
+	volumeInstalledDesc     *prometheus.Desc
+	hotfixInfoDesc          *prometheus.Desc
+	clockDesc               *prometheus.Desc
+	client                  *Client
+	collectorScrapeStatus   *prometheus.GaugeVec
+	collectorScrapeDuration *prometheus.SummaryVec
+}
+
+type softwareVolumesResponse struct {
+	Items []struct {
+		Name    string `json:"name"`
+		Product string `json:"product"`
+		Version string `json:"version"`
+		Build   string `json:"build"`
+		Status  string `json:"status"`
+		Active  bool   `json:"active"`
+	} `json:"items"`
+}
+
+type softwareHotfixesResponse struct {
+	Items []struct {
+		Name    string `json:"name"`
+		ID      string `json:"id"`
+		Product string `json:"product"`
+		Version string `json:"version"`
+		Build   string `json:"build"`
+		Title   string `json:"title"`
+	} `json:"items"`
+}
+
+// NewSoftwareCollector returns a collector that collecting the software version and volumes
+func NewSoftwareCollector(client *Client, namespace string) (*SoftwareCollector, error) {
+	subsystem := "software"
+	return &SoftwareCollector{
+		versionInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "version_info"),
+			"version_info",
+			[]string{"product", "version", "build", "edition"},
+			nil,
+		),
+		volumeInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "volume_info"),
+			"volume_info",
+			[]string{"volume", "product", "version", "build", "status"},
+			nil,
+		),
+		volumeActiveDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "volume_active"),
+			"volume_active",
+			[]string{"volume"},
+			nil,
+		),
+		volumeInstalledDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "volume_installed"),
+			"volume_installed",
+			[]string{"volume"},
+			nil,
+		),
+		hotfixInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "hotfix_info"),
+			"hotfix_info",
+			[]string{"hotfix", "id", "product", "version", "build", "title"},
+			nil,
+		),
+		clockDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "system", "clock_timestamp_seconds"),
+			"clock_timestamp_seconds",
+			nil,
+			nil,
+		),
+		collectorScrapeStatus: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_status",
+				Help:      "collector_scrape_status",
+			},
+			[]string{"collector"},
+		),
+		collectorScrapeDuration: prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_duration",
+				Help:      "collector_scrape_duration",
+			},
+			[]string{"collector"},
+		),
+		client: client,
+	}, nil
+}
+
+// Collect collects metrics for the BIG-IP software.
+func (c *SoftwareCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	err := c.collect(ch)
+	if err != nil {
+		c.collectorScrapeStatus.WithLabelValues("software").Set(float64(0))
+		logger.Warningf("Failed to get software (%s)", err)
+	} else {
+		c.collectorScrapeStatus.WithLabelValues("software").Set(float64(1))
+		logger.Debugf("Successfully fetched software")
+	}
+
+	elapsed := time.Since(start)
+	c.collectorScrapeDuration.WithLabelValues("software").Observe(float64(elapsed.Seconds()))
+	c.collectorScrapeStatus.Collect(ch)
+	c.collectorScrapeDuration.Collect(ch)
+	logger.Debugf("Getting software took %s", elapsed)
+}
+
+func (c *SoftwareCollector) collect(ch chan<- prometheus.Metric) error {
+	var version statsResponse
+	if err := c.client.get("/mgmt/tm/sys/version", &version); err != nil {
+		return err
+	}
+	for _, versionStats := range version.Entries {
+		entries := versionStats.NestedStats.Entries
+		ch <- prometheus.MustNewConstMetric(c.versionInfoDesc, prometheus.GaugeValue, 1,
+			entries.description("Product"), entries.description("Version"), entries.description("Build"), entries.description("Edition"))
+		break
+	}
+
+	var volumes softwareVolumesResponse
+	if err := c.client.get("/mgmt/tm/sys/software/volume", &volumes); err != nil {
+		return err
+	}
+	for _, volume := range volumes.Items {
+		ch <- prometheus.MustNewConstMetric(c.volumeInfoDesc, prometheus.GaugeValue, 1, volume.Name, volume.Product, volume.Version, volume.Build, volume.Status)
+		ch <- prometheus.MustNewConstMetric(c.volumeActiveDesc, prometheus.GaugeValue, boolToFloat64(volume.Active), volume.Name)
+		ch <- prometheus.MustNewConstMetric(c.volumeInstalledDesc, prometheus.GaugeValue, boolToFloat64(volume.Status == "complete"), volume.Name)
+	}
+
+	var hotfixes softwareHotfixesResponse
+	if err := c.client.get("/mgmt/tm/sys/software/hotfix", &hotfixes); err != nil {
+		return err
+	}
+	for _, hotfix := range hotfixes.Items {
+		ch <- prometheus.MustNewConstMetric(c.hotfixInfoDesc, prometheus.GaugeValue, 1, hotfix.Name, hotfix.ID, hotfix.Product, hotfix.Version, hotfix.Build, hotfix.Title)
+	}
+
+	// iControl REST does not report the uptime. Reading it through
+	// /mgmt/tm/util/bash would need an administrator with shell access,
+	// so the clock of the device is exported instead to show whether it
+	// drifted.
+	var clock statsResponse
+	if err := c.client.get("/mgmt/tm/sys/clock", &clock); err != nil {
+		return err
+	}
+	for _, clockStats := range clock.Entries {
+		if now, ok := parseTimestamp(clockStats.NestedStats.Entries.description("fullDate")); ok {
+			ch <- prometheus.MustNewConstMetric(c.clockDesc, prometheus.GaugeValue, now)
+		}
+		break
+	}
+	return nil
+}
+
+// Describe describes the metrics exported from this collector.
+func (c *SoftwareCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.versionInfoDesc
+	ch <- c.volumeInfoDesc
+	ch <- c.volumeActiveDesc
+	ch <- c.volumeInstalledDesc
+	ch <- c.hotfixInfoDesc
+	ch <- c.clockDesc
+	c.collectorScrapeStatus.Describe(ch)
+	c.collectorScrapeDuration.Describe(ch)
+}
@@ -0,0 +1,449 @@
+package collector
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	sslProtocolUsesPrefix = "common.protocolUses."
+	sslCipherUsesPrefix   = "common.cipherUses."
+)
+
+// sslProfileTypes maps the profile types read by the SSLCollector to their
+// stats endpoints.
+var sslProfileTypes = map[string]string{
+	"client-ssl": "/mgmt/tm/ltm/profile/client-ssl/stats",
+	"server-ssl": "/mgmt/tm/ltm/profile/server-ssl/stats",
+}
+
+// A SSLCollector implements the prometheus.Collector.
+type SSLCollector struct {
+	metrics                 map[string]statsMetric
+	protocolUsesDesc        *prometheus.Desc
+	cipherUsesDesc          *prometheus.Desc
+	client                  *Client
+	partitionsList          []string
+	collectorScrapeStatus   *prometheus.GaugeVec
+	collectorScrapeDuration *prometheus.SummaryVec
+}
+
+// NewSSLCollector returns a collector that collecting client-ssl and server-ssl profile statistics
+func NewSSLCollector(client *Client, namespace string, partitionsList []string) (*SSLCollector, error) {
+	var (
+		subsystem  = "ssl"
+		labelNames = []string{"partition", "profile", "profile_type"}
+	)
+	return &SSLCollector{
+		metrics: map[string]statsMetric{
+			"common.currentConnections": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "current_connections"),
+					"current_connections",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("common.currentConnections")
+				},
+				valueType: prometheus.GaugeValue,
+			},
+			"common.currentNativeConnections": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "current_native_connections"),
+					"current_native_connections",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("common.currentNativeConnections")
+				},
+				valueType: prometheus.GaugeValue,
+			},
+			"common.currentCompatibleConnections": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "current_compatible_connections"),
+					"current_compatible_connections",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("common.currentCompatibleConnections")
+				},
+				valueType: prometheus.GaugeValue,
+			},
+			"common.currentActiveHandshakes": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "current_active_handshakes"),
+					"current_active_handshakes",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("common.currentActiveHandshakes")
+				},
+				valueType: prometheus.GaugeValue,
+			},
+			"common.totNativeConns": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "tot_native_conns"),
+					"tot_native_conns",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("common.totNativeConns")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"common.totCompatConns": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "tot_compat_conns"),
+					"tot_compat_conns",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("common.totCompatConns")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"common.secureHandshakes": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "secure_handshakes"),
+					"secure_handshakes",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("common.secureHandshakes")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"common.handshakeFailures": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "handshake_failures"),
+					"handshake_failures",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("common.handshakeFailures")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"common.insecureHandshakeAccepts": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "insecure_handshake_accepts"),
+					"insecure_handshake_accepts",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("common.insecureHandshakeAccepts")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"common.insecureHandshakeRejects": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "insecure_handshake_rejects"),
+					"insecure_handshake_rejects",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("common.insecureHandshakeRejects")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"common.insecureRenegotiationRejects": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "insecure_renegotiation_rejects"),
+					"insecure_renegotiation_rejects",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("common.insecureRenegotiationRejects")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"common.midstreamRenegotiations": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "midstream_renegotiations"),
+					"midstream_renegotiations",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("common.midstreamRenegotiations")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"common.fatalAlerts": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "fatal_alerts"),
+					"fatal_alerts",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("common.fatalAlerts")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"common.sessCacheCurEntries": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "sess_cache_cur_entries"),
+					"sess_cache_cur_entries",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("common.sessCacheCurEntries")
+				},
+				valueType: prometheus.GaugeValue,
+			},
+			"common.sessCacheLookups": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "sess_cache_lookups"),
+					"sess_cache_lookups",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("common.sessCacheLookups")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"common.sessCacheHits": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "sess_cache_hits"),
+					"sess_cache_hits",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("common.sessCacheHits")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"common.sessCacheMisses": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "sess_cache_misses"),
+					"sess_cache_misses",
+					labelNames,
+					nil,
+				),
+				// The BIG-IP has no miss counter; every lookup that is not a
+				// hit is a miss.
+				extract: func(entries statsEntries) float64 {
+					return entries.value("common.sessCacheLookups") - entries.value("common.sessCacheHits")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"common.sessCacheOverflows": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "sess_cache_overflows"),
+					"sess_cache_overflows",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("common.sessCacheOverflows")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"common.sessCacheInvalidations": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "sess_cache_invalidations"),
+					"sess_cache_invalidations",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("common.sessCacheInvalidations")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"common.sesstickUses.reused": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "sesstick_uses_reused"),
+					"sesstick_uses_reused",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("common.sesstickUses.reused")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"common.sesstickUses.reuseFailed": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "sesstick_uses_reuse_failed"),
+					"sesstick_uses_reuse_failed",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("common.sesstickUses.reuseFailed")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"common.decryptedBytesIn": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "decrypted_bytes_in"),
+					"decrypted_bytes_in",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("common.decryptedBytesIn")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"common.decryptedBytesOut": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "decrypted_bytes_out"),
+					"decrypted_bytes_out",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("common.decryptedBytesOut")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"common.encryptedBytesIn": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "encrypted_bytes_in"),
+					"encrypted_bytes_in",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("common.encryptedBytesIn")
+				},
+				valueType: prometheus.CounterValue,
+			},
+			"common.encryptedBytesOut": {
+				desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "encrypted_bytes_out"),
+					"encrypted_bytes_out",
+					labelNames,
+					nil,
+				),
+				extract: func(entries statsEntries) float64 {
+					return entries.value("common.encryptedBytesOut")
+				},
+				valueType: prometheus.CounterValue,
+			},
+		},
+		protocolUsesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "protocol_uses"),
+			"protocol_uses",
+			append(labelNames, "protocol"),
+			nil,
+		),
+		cipherUsesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "cipher_uses"),
+			"cipher_uses",
+			append(labelNames, "algorithm", "kind"),
+			nil,
+		),
+		collectorScrapeStatus: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_status",
+				Help:      "collector_scrape_status",
+			},
+			[]string{"collector"},
+		),
+		collectorScrapeDuration: prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_duration",
+				Help:      "collector_scrape_duration",
+			},
+			[]string{"collector"},
+		),
+		client:         client,
+		partitionsList: partitionsList,
+	}, nil
+}
+
+// Collect collects metrics for BIG-IP client-ssl and server-ssl profiles.
+func (c *SSLCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	status := float64(1)
+	for profileType, path := range sslProfileTypes {
+		var allProfileStats statsResponse
+		if err := c.client.get(path, &allProfileStats); err != nil {
+			status = 0
+			logger.Warningf("Failed to get statistics for %s profiles (%s)", profileType, err)
+			continue
+		}
+		for _, profileStats := range allProfileStats.Entries {
+			entries := profileStats.NestedStats.Entries
+			partition, profileName := splitFullPath(entries.description("tmName"))
+
+			if c.partitionsList != nil && !stringInSlice(partition, c.partitionsList) {
+				continue
+			}
+
+			labels := []string{partition, profileName, profileType}
+			for _, metric := range c.metrics {
+				ch <- prometheus.MustNewConstMetric(metric.desc, metric.valueType, metric.extract(entries), labels...)
+			}
+			c.collectUses(ch, entries, labels)
+		}
+		logger.Debugf("Successfully fetched statistics for %s profiles", profileType)
+	}
+	c.collectorScrapeStatus.WithLabelValues("ssl").Set(status)
+
+	elapsed := time.Since(start)
+	c.collectorScrapeDuration.WithLabelValues("ssl").Observe(float64(elapsed.Seconds()))
+	c.collectorScrapeStatus.Collect(ch)
+	c.collectorScrapeDuration.Collect(ch)
+	logger.Debugf("Getting ssl profile statistics took %s", elapsed)
+}
+
+// collectUses exports the protocol version and cipher counters of a profile.
+// Cipher counters are named after the algorithm and its kind, e.g.
+// ecdheRsaKeyxchg, aesGcmBulk or shaDigest.
+func (c *SSLCollector) collectUses(ch chan<- prometheus.Metric, entries statsEntries, labels []string) {
+	for name, entry := range entries {
+		switch {
+		case strings.HasPrefix(name, sslProtocolUsesPrefix):
+			protocol := strings.TrimPrefix(name, sslProtocolUsesPrefix)
+			ch <- prometheus.MustNewConstMetric(c.protocolUsesDesc, prometheus.CounterValue, entry.Value, append(labels, protocol)...)
+		case strings.HasPrefix(name, sslCipherUsesPrefix):
+			algorithm, kind := splitCipherUse(strings.TrimPrefix(name, sslCipherUsesPrefix))
+			ch <- prometheus.MustNewConstMetric(c.cipherUsesDesc, prometheus.CounterValue, entry.Value, append(labels, algorithm, kind)...)
+		}
+	}
+}
+
+// splitCipherUse splits a cipher counter name like ecdheRsaKeyxchg into
+// the algorithm ecdhe_rsa and the kind keyxchg.
+func splitCipherUse(name string) (string, string) {
+	for _, kind := range []string{"Keyxchg", "Bulk", "Digest"} {
+		if strings.HasSuffix(name, kind) {
+			return toSnakeCase(strings.TrimSuffix(name, kind)), strings.ToLower(kind)
+		}
+	}
+	return toSnakeCase(name), ""
+}
+
+// Describe describes the metrics exported from this collector.
+func (c *SSLCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, metric := range c.metrics {
+		ch <- metric.desc
+	}
+	ch <- c.protocolUsesDesc
+	ch <- c.cipherUsesDesc
+	c.collectorScrapeStatus.Describe(ch)
+	c.collectorScrapeDuration.Describe(ch)
+}
@@ -0,0 +1,534 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// A TCPProfileCollector implements the prometheus.Collector.
+type TCPProfileCollector struct {
+	tcpMetrics              map[string]statsMetric
+	fastL4Metrics           map[string]statsMetric
+	client                  *Client
+	partitionsList          []string
+	collectorScrapeStatus   *prometheus.GaugeVec
+	collectorScrapeDuration *prometheus.SummaryVec
+}
+
+// NewTCPProfileCollector returns a collector that collecting tcp and fastl4 profile statistics
+func NewTCPProfileCollector(client *Client, namespace string, partitionsList []string) (*TCPProfileCollector, error) {
+	var (
+		subsystem  = "tcp_profile"
+		labelNames = []string{"partition", "profile"}
+	)
+	// The profile statistics hold no round trip times, those are only
+	// measured by the TCP analytics of AVR.
+	tcpMetrics := map[string]statsMetric{
+		"open": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "open"),
+				"open",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("open")
+			},
+			valueType: prometheus.GaugeValue,
+		},
+		"closeWait": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "close_wait"),
+				"close_wait",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("closeWait")
+			},
+			valueType: prometheus.GaugeValue,
+		},
+		"finWait": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "fin_wait"),
+				"fin_wait",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("finWait")
+			},
+			valueType: prometheus.GaugeValue,
+		},
+		"timeWait": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "time_wait"),
+				"time_wait",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("timeWait")
+			},
+			valueType: prometheus.GaugeValue,
+		},
+		"accepts": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "accepts"),
+				"accepts",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("accepts")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"acceptfails": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "acceptfails"),
+				"acceptfails",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("acceptfails")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"connects": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "connects"),
+				"connects",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("connects")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"connfails": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "connfails"),
+				"connfails",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("connfails")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"expires": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "expires"),
+				"expires",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("expires")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"abandons": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "abandons"),
+				"abandons",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("abandons")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"rxrst": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "rxrst"),
+				"rxrst",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("rxrst")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"rxbadsum": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "rxbadsum"),
+				"rxbadsum",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("rxbadsum")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"rxbadseg": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "rxbadseg"),
+				"rxbadseg",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("rxbadseg")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"rxooseg": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "rxooseg"),
+				"rxooseg",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("rxooseg")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"rxcookie": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "rxcookie"),
+				"rxcookie",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("rxcookie")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"rxbadcookie": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "rxbadcookie"),
+				"rxbadcookie",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("rxbadcookie")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"syncacheover": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "syncacheover"),
+				"syncacheover",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("syncacheover")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"txrexmits": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "txrexmits"),
+				"txrexmits",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("txrexmits")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"zeroWindow": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "zero_window"),
+				"zero_window",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("zeroWindow")
+			},
+			valueType: prometheus.CounterValue,
+		},
+	}
+
+	subsystem = "fastl4_profile"
+	fastL4Metrics := map[string]statsMetric{
+		"open": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "open"),
+				"open",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("open")
+			},
+			valueType: prometheus.GaugeValue,
+		},
+		"accepts": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "accepts"),
+				"accepts",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("accepts")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"acceptfails": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "acceptfails"),
+				"acceptfails",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("acceptfails")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"connects": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "connects"),
+				"connects",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("connects")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"connfails": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "connfails"),
+				"connfails",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("connfails")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"expires": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "expires"),
+				"expires",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("expires")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"rxbadpkt": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "rxbadpkt"),
+				"rxbadpkt",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("rxbadpkt")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"rxunreach": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "rxunreach"),
+				"rxunreach",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("rxunreach")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"rxbadunreach": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "rxbadunreach"),
+				"rxbadunreach",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("rxbadunreach")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"rxbadsum": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "rxbadsum"),
+				"rxbadsum",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("rxbadsum")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"rxbadseg": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "rxbadseg"),
+				"rxbadseg",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("rxbadseg")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"rxcookie": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "rxcookie"),
+				"rxcookie",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("rxcookie")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"rxbadcookie": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "rxbadcookie"),
+				"rxbadcookie",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("rxbadcookie")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"syncookIssue": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "syncook_issue"),
+				"syncook_issue",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("syncookIssue")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"syncookAccept": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "syncook_accept"),
+				"syncook_accept",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("syncookAccept")
+			},
+			valueType: prometheus.CounterValue,
+		},
+		"syncookReject": {
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, "syncook_reject"),
+				"syncook_reject",
+				labelNames,
+				nil,
+			),
+			extract: func(entries statsEntries) float64 {
+				return entries.value("syncookReject")
+			},
+			valueType: prometheus.CounterValue,
+		},
+	}
+
+	return &TCPProfileCollector{
+		tcpMetrics:    tcpMetrics,
+		fastL4Metrics: fastL4Metrics,
+		collectorScrapeStatus: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_status",
+				Help:      "collector_scrape_status",
+			},
+			[]string{"collector"},
+		),
+		collectorScrapeDuration: prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_duration",
+				Help:      "collector_scrape_duration",
+			},
+			[]string{"collector"},
+		),
+		client:         client,
+		partitionsList: partitionsList,
+	}, nil
+}
+
+// Collect collects metrics for BIG-IP tcp and fastl4 profiles.
+func (c *TCPProfileCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	tcpErr := c.collectProfiles(ch, "/mgmt/tm/ltm/profile/tcp/stats", c.tcpMetrics)
+	if tcpErr != nil {
+		logger.Warningf("Failed to get statistics for tcp profiles (%s)", tcpErr)
+	}
+	fastL4Err := c.collectProfiles(ch, "/mgmt/tm/ltm/profile/fastl4/stats", c.fastL4Metrics)
+	if fastL4Err != nil {
+		logger.Warningf("Failed to get statistics for fastl4 profiles (%s)", fastL4Err)
+	}
+	if tcpErr != nil || fastL4Err != nil {
+		c.collectorScrapeStatus.WithLabelValues("tcp_profile").Set(float64(0))
+	} else {
+		c.collectorScrapeStatus.WithLabelValues("tcp_profile").Set(float64(1))
+		logger.Debugf("Successfully fetched statistics for tcp and fastl4 profiles")
+	}
+
+	elapsed := time.Since(start)
+	c.collectorScrapeDuration.WithLabelValues("tcp_profile").Observe(float64(elapsed.Seconds()))
+	c.collectorScrapeStatus.Collect(ch)
+	c.collectorScrapeDuration.Collect(ch)
+	logger.Debugf("Getting tcp and fastl4 profile statistics took %s", elapsed)
+}
+
+func (c *TCPProfileCollector) collectProfiles(ch chan<- prometheus.Metric, path string, metrics map[string]statsMetric) error {
+	var allProfileStats statsResponse
+	if err := c.client.get(path, &allProfileStats); err != nil {
+		return err
+	}
+	for _, profileStats := range allProfileStats.Entries {
+		entries := profileStats.NestedStats.Entries
+		partition, profileName := splitFullPath(entries.description("tmName"))
+
+		if c.partitionsList != nil && !stringInSlice(partition, c.partitionsList) {
+			continue
+		}
+
+		labels := []string{partition, profileName}
+		for _, metric := range metrics {
+			ch <- prometheus.MustNewConstMetric(metric.desc, metric.valueType, metric.extract(entries), labels...)
+		}
+	}
+	return nil
+}
+
+// Describe describes the metrics exported from this collector.
+func (c *TCPProfileCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, metric := range c.tcpMetrics {
+		ch <- metric.desc
+	}
+	for _, metric := range c.fastL4Metrics {
+		ch <- metric.desc
+	}
+	c.collectorScrapeStatus.Describe(ch)
+	c.collectorScrapeDuration.Describe(ch)
+}
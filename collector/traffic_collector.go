@@ -0,0 +1,174 @@
+package collector
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// A TrafficCollector implements the prometheus.Collector.
+type TrafficCollector struct {
+	bytesDesc               *prometheus.Desc
+	packetsDesc             *prometheus.Desc
+	connectionsDesc         *prometheus.Desc
+	currentConnectionsDesc  *prometheus.Desc
+	performanceDesc         *prometheus.Desc
+	client                  *Client
+	collectorScrapeStatus   *prometheus.GaugeVec
+	collectorScrapeDuration *prometheus.SummaryVec
+}
+
+// trafficSides maps the side label of the traffic metrics to the prefix of
+// its stats in /mgmt/tm/sys/tmm-traffic.
+var trafficSides = map[string]string{
+	"client": "clientSideTraffic.",
+	"server": "serverSideTraffic.",
+}
+
+// NewTrafficCollector returns a collector that collecting device wide traffic statistics
+func NewTrafficCollector(client *Client, namespace string) (*TrafficCollector, error) {
+	subsystem := "traffic"
+	return &TrafficCollector{
+		bytesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "bytes_total"),
+			"bytes_total",
+			[]string{"side", "direction"},
+			nil,
+		),
+		packetsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "packets_total"),
+			"packets_total",
+			[]string{"side", "direction"},
+			nil,
+		),
+		connectionsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "connections_total"),
+			"connections_total",
+			[]string{"side"},
+			nil,
+		),
+		currentConnectionsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "current_connections"),
+			"current_connections",
+			[]string{"side"},
+			nil,
+		),
+		performanceDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "performance", "current"),
+			"current",
+			[]string{"graph", "stat"},
+			nil,
+		),
+		collectorScrapeStatus: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_status",
+				Help:      "collector_scrape_status",
+			},
+			[]string{"collector"},
+		),
+		collectorScrapeDuration: prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_duration",
+				Help:      "collector_scrape_duration",
+			},
+			[]string{"collector"},
+		),
+		client: client,
+	}, nil
+}
+
+// Collect collects device wide traffic metrics of the BIG-IP. They
+// complement the per object metrics of the vs and pool collectors.
+func (c *TrafficCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	err := c.collectTMMTraffic(ch)
+	if err != nil {
+		logger.Warningf("Failed to get tmm traffic (%s)", err)
+	}
+	if perfErr := c.collectPerformance(ch); perfErr != nil {
+		logger.Warningf("Failed to get performance (%s)", perfErr)
+		err = perfErr
+	}
+	if err != nil {
+		c.collectorScrapeStatus.WithLabelValues("traffic").Set(float64(0))
+	} else {
+		c.collectorScrapeStatus.WithLabelValues("traffic").Set(float64(1))
+		logger.Debugf("Successfully fetched traffic")
+	}
+
+	elapsed := time.Since(start)
+	c.collectorScrapeDuration.WithLabelValues("traffic").Observe(float64(elapsed.Seconds()))
+	c.collectorScrapeStatus.Collect(ch)
+	c.collectorScrapeDuration.Collect(ch)
+	logger.Debugf("Getting traffic took %s", elapsed)
+}
+
+// collectTMMTraffic exports the traffic counters summed over all TMMs.
+func (c *TrafficCollector) collectTMMTraffic(ch chan<- prometheus.Metric) error {
+	var tmmTraffic statsResponse
+	if err := c.client.get("/mgmt/tm/sys/tmm-traffic", &tmmTraffic); err != nil {
+		return err
+	}
+	sums := make(map[string]float64)
+	for _, tmm := range tmmTraffic.Entries {
+		for name, entry := range tmm.NestedStats.Entries {
+			sums[name] += entry.Value
+		}
+	}
+	for side, prefix := range trafficSides {
+		ch <- prometheus.MustNewConstMetric(c.bytesDesc, prometheus.CounterValue, sums[prefix+"bitsIn"]/8, side, "in")
+		ch <- prometheus.MustNewConstMetric(c.bytesDesc, prometheus.CounterValue, sums[prefix+"bitsOut"]/8, side, "out")
+		ch <- prometheus.MustNewConstMetric(c.packetsDesc, prometheus.CounterValue, sums[prefix+"pktsIn"], side, "in")
+		ch <- prometheus.MustNewConstMetric(c.packetsDesc, prometheus.CounterValue, sums[prefix+"pktsOut"], side, "out")
+		ch <- prometheus.MustNewConstMetric(c.connectionsDesc, prometheus.CounterValue, sums[prefix+"totConns"], side)
+		ch <- prometheus.MustNewConstMetric(c.currentConnectionsDesc, prometheus.GaugeValue, sums[prefix+"curConns"], side)
+	}
+	return nil
+}
+
+// collectPerformance exports the current value of every stat of the
+// performance graphs in the GUI, e.g. "New TCP Accepts", "HTTP Requests" or
+// "SSL TPS". They are rates, so they are exported as is. Stats are labelled
+// with the graph endpoint they are read from, as the same stat names appear
+// in several of them.
+func (c *TrafficCollector) collectPerformance(ch chan<- prometheus.Metric) error {
+	for _, graph := range []string{"all-stats", "throughput"} {
+		var performance statsResponse
+		if err := c.client.get("/mgmt/tm/sys/performance/"+graph, &performance); err != nil {
+			return err
+		}
+		for key, stat := range performance.Entries {
+			// The key is the link of the stat, e.g.
+			// https://localhost/mgmt/tm/sys/performance/all-stats/HTTP%20Requests
+			name := key[strings.LastIndex(key, "/")+1:]
+			if i := strings.Index(key, "/performance/"+graph+"/"); i >= 0 {
+				name = key[i+len("/performance/"+graph+"/"):]
+			}
+			if unescaped, err := url.PathUnescape(name); err == nil {
+				name = unescaped
+			}
+			value, err := strconv.ParseFloat(stat.NestedStats.Entries.description("Current"), 64)
+			if err != nil {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(c.performanceDesc, prometheus.GaugeValue, value, graph, name)
+		}
+	}
+	return nil
+}
+
+// Describe describes the metrics exported from this collector.
+func (c *TrafficCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.bytesDesc
+	ch <- c.packetsDesc
+	ch <- c.connectionsDesc
+	ch <- c.currentConnectionsDesc
+	ch <- c.performanceDesc
+	c.collectorScrapeStatus.Describe(ch)
+	c.collectorScrapeDuration.Describe(ch)
+}
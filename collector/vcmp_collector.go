@@ -0,0 +1,183 @@
+package collector
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// A VCMPCollector implements the prometheus.Collector.
+type VCMPCollector struct {
+	guestInfoDesc           *prometheus.Desc
+	guestSlotsDesc          *prometheus.Desc
+	guestCoresDesc          *prometheus.Desc
+	guestCPUDesc            *prometheus.Desc
+	guestMemoryUsedDesc     *prometheus.Desc
+	guestMemoryTotalDesc    *prometheus.Desc
+	diskUsedDesc            *prometheus.Desc
+	diskSizeDesc            *prometheus.Desc
+	client                  *Client
+	collectorScrapeStatus   *prometheus.GaugeVec
+	collectorScrapeDuration *prometheus.SummaryVec
+}
+
+type vcmpGuestsResponse struct {
+	Items []struct {
+		Name         string `json:"name"`
+		State        string `json:"state"`
+		ManagementIP string `json:"managementIp"`
+		InitialImage string `json:"initialImage"`
+		CoresPerSlot int    `json:"coresPerSlot"`
+		Slots        int    `json:"slots"`
+	} `json:"items"`
+}
+
+// NewVCMPCollector returns a collector that collecting vCMP guests
+func NewVCMPCollector(client *Client, namespace string) (*VCMPCollector, error) {
+	var (
+		subsystem       = "vcmp"
+		guestSlotLabels = []string{"guest", "slot"}
+	)
+	return &VCMPCollector{
+		guestInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "guest_info"),
+			"guest_info",
+			[]string{"guest", "state", "management_ip", "initial_image"},
+			nil,
+		),
+		guestSlotsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "guest_slots"),
+			"guest_slots",
+			[]string{"guest"},
+			nil,
+		),
+		guestCoresDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "guest_cores"),
+			"guest_cores",
+			[]string{"guest"},
+			nil,
+		),
+		guestCPUDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "guest_cpu_usage_percent"),
+			"guest_cpu_usage_percent",
+			guestSlotLabels,
+			nil,
+		),
+		guestMemoryUsedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "guest_memory_used_bytes"),
+			"guest_memory_used_bytes",
+			guestSlotLabels,
+			nil,
+		),
+		guestMemoryTotalDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "guest_memory_total_bytes"),
+			"guest_memory_total_bytes",
+			guestSlotLabels,
+			nil,
+		),
+		diskUsedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "virtual_disk_used_bytes"),
+			"virtual_disk_used_bytes",
+			[]string{"disk", "slot"},
+			nil,
+		),
+		diskSizeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "virtual_disk_size_bytes"),
+			"virtual_disk_size_bytes",
+			[]string{"disk", "slot"},
+			nil,
+		),
+		collectorScrapeStatus: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_status",
+				Help:      "collector_scrape_status",
+			},
+			[]string{"collector"},
+		),
+		collectorScrapeDuration: prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace: namespace,
+				Name:      "collector_scrape_duration",
+				Help:      "collector_scrape_duration",
+			},
+			[]string{"collector"},
+		),
+		client: client,
+	}, nil
+}
+
+// Collect collects metrics for the vCMP guests of a BIG-IP. Nothing is
+// collected unless the target is a vCMP host.
+func (c *VCMPCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	provisioned, err := c.client.provisioned("vcmp")
+	if err == nil && provisioned {
+		err = c.collect(ch)
+	}
+	if err != nil {
+		c.collectorScrapeStatus.WithLabelValues("vcmp").Set(float64(0))
+		logger.Warningf("Failed to get vCMP guests (%s)", err)
+	} else {
+		c.collectorScrapeStatus.WithLabelValues("vcmp").Set(float64(1))
+		logger.Debugf("Successfully fetched vCMP guests")
+	}
+
+	elapsed := time.Since(start)
+	c.collectorScrapeDuration.WithLabelValues("vcmp").Observe(float64(elapsed.Seconds()))
+	c.collectorScrapeStatus.Collect(ch)
+	c.collectorScrapeDuration.Collect(ch)
+	logger.Debugf("Getting vCMP guests took %s", elapsed)
+}
+
+func (c *VCMPCollector) collect(ch chan<- prometheus.Metric) error {
+	var guests vcmpGuestsResponse
+	if err := c.client.get("/mgmt/tm/vcmp/guest", &guests); err != nil {
+		return err
+	}
+	for _, guest := range guests.Items {
+		ch <- prometheus.MustNewConstMetric(c.guestInfoDesc, prometheus.GaugeValue, 1, guest.Name, guest.State, guest.ManagementIP, guest.InitialImage)
+		ch <- prometheus.MustNewConstMetric(c.guestSlotsDesc, prometheus.GaugeValue, float64(guest.Slots), guest.Name)
+		ch <- prometheus.MustNewConstMetric(c.guestCoresDesc, prometheus.GaugeValue, float64(guest.Slots*guest.CoresPerSlot), guest.Name)
+	}
+
+	// Usage is reported per slot the guest runs on.
+	var guestStats statsResponse
+	if err := c.client.get("/mgmt/tm/vcmp/guest/stats", &guestStats); err != nil {
+		return err
+	}
+	for _, stats := range guestStats.Entries {
+		entries := stats.NestedStats.Entries
+		labels := []string{entries.description("tmName"), fmt.Sprint(entries.value("slotId"))}
+		ch <- prometheus.MustNewConstMetric(c.guestCPUDesc, prometheus.GaugeValue, entries.value("cpuUsage"), labels...)
+		ch <- prometheus.MustNewConstMetric(c.guestMemoryUsedDesc, prometheus.GaugeValue, entries.value("memoryUsed"), labels...)
+		ch <- prometheus.MustNewConstMetric(c.guestMemoryTotalDesc, prometheus.GaugeValue, entries.value("memoryTotal"), labels...)
+	}
+
+	var diskStats statsResponse
+	if err := c.client.get("/mgmt/tm/vcmp/virtual-disk/stats", &diskStats); err != nil {
+		return err
+	}
+	for _, stats := range diskStats.Entries {
+		entries := stats.NestedStats.Entries
+		labels := []string{entries.description("tmName"), fmt.Sprint(entries.value("slotId"))}
+		ch <- prometheus.MustNewConstMetric(c.diskUsedDesc, prometheus.GaugeValue, entries.value("diskUse"), labels...)
+		ch <- prometheus.MustNewConstMetric(c.diskSizeDesc, prometheus.GaugeValue, entries.value("diskSize"), labels...)
+	}
+	return nil
+}
+
+// Describe describes the metrics exported from this collector.
+func (c *VCMPCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.guestInfoDesc
+	ch <- c.guestSlotsDesc
+	ch <- c.guestCoresDesc
+	ch <- c.guestCPUDesc
+	ch <- c.guestMemoryUsedDesc
+	ch <- c.guestMemoryTotalDesc
+	ch <- c.diskUsedDesc
+	ch <- c.diskSizeDesc
+	c.collectorScrapeStatus.Describe(ch)
+	c.collectorScrapeDuration.Describe(ch)
+}
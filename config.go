@@ -1,16 +1,68 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
 	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/log"
 	yaml "gopkg.in/yaml.v2"
 )
 
+var (
+	envVarRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+	configReloadSuccess = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "bigip_exporter",
+		Name:      "config_last_reload_successful",
+		Help:      "Whether the last configuration reload attempt was successful.",
+	})
+	configReloadSeconds = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "bigip_exporter",
+		Name:      "config_last_reload_success_timestamp_seconds",
+		Help:      "Timestamp of the last successful configuration reload.",
+	})
+)
+
+// passwordCommandTimeout bounds how long a password_command may run, so a
+// hanging command cannot block loading the config.
+var passwordCommandTimeout = 30 * time.Second
+
+// Kinds of credentials keys, in order of precedence.
+const (
+	matchExact   = "exact"
+	matchCIDR    = "cidr"
+	matchGlob    = "glob"
+	matchRegex   = "regex"
+	matchDefault = "default"
+)
+
 // Config is the Go representation of the yaml config file.
 type Config struct {
 	Credentials map[string]Credentials `yaml:"credentials"`
+
+	rules []credentialsRule
+}
+
+// credentialsRule is a key of the credentials section compiled into a
+// matcher for targets.
+type credentialsRule struct {
+	key         string
+	kind        string
+	network     *net.IPNet
+	regexp      *regexp.Regexp
+	credentials Credentials
 }
 
 // SafeConfig wraps Config for concurrency-safe operations.
@@ -22,22 +74,167 @@ type SafeConfig struct {
 // Credentials is the Go representation of the credentials section in the yaml
 // config file.
 type Credentials struct {
-	User     string `yaml:"user"`
-	Password string `yaml:"pass"`
-	BasicAuth bool  `yaml:"basic_auth"`
+	User            string `yaml:"user"`
+	Password        string `yaml:"pass"`
+	PasswordFile    string `yaml:"pass_file"`
+	PasswordCommand string `yaml:"password_command"`
+	BasicAuth       bool   `yaml:"basic_auth"`
+	LoginProvider   string `yaml:"login_provider"`
+}
+
+// resolve expands ${ENV_VAR} references in the user and password and reads
+// the password from pass_file or password_command when one of them is set.
+func (c *Credentials) resolve() error {
+	sources := 0
+	for _, s := range []string{c.Password, c.PasswordFile, c.PasswordCommand} {
+		if s != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return fmt.Errorf("only one of pass, pass_file and password_command may be set")
+	}
+
+	var err error
+	if c.User, err = expandEnv(c.User); err != nil {
+		return err
+	}
+	switch {
+	case c.PasswordFile != "":
+		passwordFile, err := expandEnv(c.PasswordFile)
+		if err != nil {
+			return err
+		}
+		b, err := ioutil.ReadFile(passwordFile)
+		if err != nil {
+			return fmt.Errorf("error reading pass_file: %s", err)
+		}
+		c.Password = strings.TrimRight(string(b), "\r\n")
+	case c.PasswordCommand != "":
+		ctx, cancel := context.WithTimeout(context.Background(), passwordCommandTimeout)
+		defer cancel()
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "/bin/sh", "-c", c.PasswordCommand)
+		cmd.Stderr = &stderr
+		// Children of the shell may keep the output open after it is
+		// killed, so do not wait for them.
+		cmd.WaitDelay = time.Second
+		out, err := cmd.Output()
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out after %s", passwordCommandTimeout)
+		}
+		if err != nil {
+			return fmt.Errorf("error running password_command: %s: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		c.Password = strings.TrimRight(string(out), "\r\n")
+	default:
+		if c.Password, err = expandEnv(c.Password); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (sc *SafeConfig) ReloadConfig(configFile string) error {
+// expandEnv replaces ${VAR} references with the value of the environment
+// variable. Unlike os.ExpandEnv a bare $ is left alone, so passwords
+// containing dollar signs do not need escaping. Referencing a variable that
+// is not set is an error.
+func expandEnv(s string) (string, error) {
+	var missing []string
+	expanded := envVarRegexp.ReplaceAllStringFunc(s, func(m string) string {
+		name := envVarRegexp.FindStringSubmatch(m)[1]
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("unset environment variable %s", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+// LoadConfig reads, strictly parses and validates a config file. All
+// problems found are reported, not only the first one.
+func LoadConfig(configFile string) (*Config, error) {
 	var c = &Config{}
 
 	yamlFile, err := ioutil.ReadFile(configFile)
 	if err != nil {
-		log.Errorf("Error reading config file: %s", err)
-		return err
+		return nil, fmt.Errorf("error reading config file: %s", err)
+	}
+
+	if err := yaml.UnmarshalStrict(yamlFile, c); err != nil {
+		return nil, fmt.Errorf("error parsing config file: %s", err)
+	}
+
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// validate checks every credential, resolves its secrets and compiles the
+// credentials keys into matchers.
+func (c *Config) validate() error {
+	var errs []string
+	if len(c.Credentials) == 0 {
+		errs = append(errs, "no credentials configured")
 	}
 
-	if err := yaml.Unmarshal(yamlFile, c); err != nil {
-		log.Errorf("Error parsing config file: %s", err)
+	keys := make([]string, 0, len(c.Credentials))
+	for key := range c.Credentials {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Secrets are resolved on every reload so rotated passwords are picked
+	// up without restarting the exporter.
+	for _, key := range keys {
+		credentials := c.Credentials[key]
+		if credentials.Password == "" && credentials.PasswordFile == "" && credentials.PasswordCommand == "" {
+			errs = append(errs, fmt.Sprintf("credentials %q: one of pass, pass_file or password_command must be set", key))
+			continue
+		}
+		if err := credentials.resolve(); err != nil {
+			errs = append(errs, fmt.Sprintf("credentials %q: %s", key, err))
+			continue
+		}
+		if credentials.User == "" {
+			errs = append(errs, fmt.Sprintf("credentials %q: user must be set", key))
+		}
+		if credentials.BasicAuth && credentials.LoginProvider != "" {
+			errs = append(errs, fmt.Sprintf("credentials %q: login_provider cannot be used with basic_auth", key))
+		}
+		c.Credentials[key] = credentials
+	}
+
+	if err := c.compileRules(); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config:\n  %s", strings.Join(errs, "\n  "))
+	}
+	return nil
+}
+
+// ReloadConfig loads the config file and swaps it in. An invalid config is
+// rejected and the previous one stays in use.
+func (sc *SafeConfig) ReloadConfig(configFile string) (err error) {
+	defer func() {
+		if err != nil {
+			configReloadSuccess.Set(0)
+		} else {
+			configReloadSuccess.Set(1)
+			configReloadSeconds.Set(float64(time.Now().Unix()))
+		}
+	}()
+
+	c, err := LoadConfig(configFile)
+	if err != nil {
+		log.Errorf("Error loading config file: %s", err)
 		return err
 	}
 
@@ -52,21 +249,123 @@ func (sc *SafeConfig) ReloadConfig(configFile string) error {
 // CredentialsForTarget returns the Credentials for a given target, or the
 // default. It is concurrency-safe.
 func (sc *SafeConfig) CredentialsForTarget(target string) (Credentials, error) {
-	sc.Lock()
-	defer sc.Unlock()
-	if credentials, ok := sc.C.Credentials[target]; ok {
-		return Credentials{
-			User:     credentials.User,
-			Password: credentials.Password,
-			BasicAuth: credentials.BasicAuth,
-		}, nil
-	}
-	if credentials, ok := sc.C.Credentials["default"]; ok {
-		return Credentials{
-			User:     credentials.User,
-			Password: credentials.Password,
-			BasicAuth: credentials.BasicAuth,
-		}, nil
-	}
-	return Credentials{}, fmt.Errorf("no credentials found for target %s", target)
+	rule, err := sc.ruleForTarget(target)
+	if err != nil {
+		return Credentials{}, err
+	}
+	return Credentials{
+		User:          rule.credentials.User,
+		Password:      rule.credentials.Password,
+		BasicAuth:     rule.credentials.BasicAuth,
+		LoginProvider: rule.credentials.LoginProvider,
+	}, nil
+}
+
+// ruleForTarget returns the credentials rule that matches a given target.
+// Exact keys take precedence over CIDR keys, which take precedence over glob
+// keys, then regex keys and finally the default. It is concurrency-safe.
+func (sc *SafeConfig) ruleForTarget(target string) (credentialsRule, error) {
+	sc.RLock()
+	defer sc.RUnlock()
+	host, _, err := net.SplitHostPort(target)
+	if err != nil {
+		host = target
+	}
+	ip := net.ParseIP(host)
+	for _, rule := range sc.C.rules {
+		if rule.matches(target, host, ip) {
+			return rule, nil
+		}
+	}
+	return credentialsRule{}, fmt.Errorf("no credentials found for target %s", target)
+}
+
+// compileRules turns the keys of the credentials section into an ordered
+// list of matchers.
+func (c *Config) compileRules() error {
+	c.rules = nil
+	for key, credentials := range c.Credentials {
+		rule := credentialsRule{key: key, credentials: credentials}
+		switch {
+		case key == "default":
+			rule.kind = matchDefault
+		case len(key) > 2 && strings.HasPrefix(key, "/") && strings.HasSuffix(key, "/"):
+			re, err := regexp.Compile("^(?:" + key[1:len(key)-1] + ")$")
+			if err != nil {
+				return fmt.Errorf("invalid regex credentials key %q: %s", key, err)
+			}
+			rule.kind = matchRegex
+			rule.regexp = re
+		case strings.Contains(key, "/"):
+			_, network, err := net.ParseCIDR(key)
+			if err != nil {
+				return fmt.Errorf("invalid CIDR credentials key %q: %s", key, err)
+			}
+			rule.kind = matchCIDR
+			rule.network = network
+		case strings.ContainsAny(key, "*?["):
+			if _, err := path.Match(key, ""); err != nil {
+				return fmt.Errorf("invalid glob credentials key %q: %s", key, err)
+			}
+			rule.kind = matchGlob
+		default:
+			rule.kind = matchExact
+		}
+		c.rules = append(c.rules, rule)
+	}
+	sort.Slice(c.rules, func(i, j int) bool {
+		a, b := c.rules[i], c.rules[j]
+		if a.kind != b.kind {
+			return matchPrecedence(a.kind) < matchPrecedence(b.kind)
+		}
+		if a.kind == matchCIDR {
+			aOnes, _ := a.network.Mask.Size()
+			bOnes, _ := b.network.Mask.Size()
+			if aOnes != bOnes {
+				return aOnes > bOnes
+			}
+		}
+		// More specific (longer) patterns win, ties are broken by key so
+		// the order does not depend on map iteration.
+		if len(a.key) != len(b.key) {
+			return len(a.key) > len(b.key)
+		}
+		return a.key < b.key
+	})
+	return nil
+}
+
+func matchPrecedence(kind string) int {
+	switch kind {
+	case matchExact:
+		return 0
+	case matchCIDR:
+		return 1
+	case matchGlob:
+		return 2
+	case matchRegex:
+		return 3
+	}
+	return 4
+}
+
+// matches reports whether the rule applies to target. Glob and regex keys
+// are tried against the target as given and against the target without its
+// port.
+func (r credentialsRule) matches(target, host string, ip net.IP) bool {
+	switch r.kind {
+	case matchExact:
+		return r.key == target
+	case matchCIDR:
+		return ip != nil && r.network.Contains(ip)
+	case matchGlob:
+		if ok, _ := path.Match(r.key, target); ok {
+			return true
+		}
+		ok, _ := path.Match(r.key, host)
+		return ok
+	case matchRegex:
+		return r.regexp.MatchString(target) || r.regexp.MatchString(host)
+	}
+	return true
 }
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, dir, content string) string {
+	t.Helper()
+	file := filepath.Join(dir, "bigip-exporter.yml")
+	if err := ioutil.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bigip_exporter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name   string
+		config string
+		errs   []string
+	}{
+		{
+			name:   "valid",
+			config: "credentials:\n  default:\n    user: admin\n    pass: secret\n",
+		},
+		{
+			name:   "unknown field",
+			config: "credentials:\n  default:\n    user: admin\n    password: secret\n",
+			errs:   []string{"error parsing config file", "password"},
+		},
+		{
+			name:   "unknown section",
+			config: "credential:\n  default:\n    user: admin\n    pass: secret\n",
+			errs:   []string{"error parsing config file", "credential"},
+		},
+		{
+			name:   "no credentials",
+			config: "credentials: {}\n",
+			errs:   []string{"no credentials configured"},
+		},
+		{
+			name: "all problems reported",
+			config: "credentials:\n" +
+				"  default:\n    pass: secret\n" +
+				"  lb01:\n    user: admin\n" +
+				"  lb02:\n    user: admin\n    pass: secret\n    pass_file: /secret\n" +
+				"  lb03:\n    user: admin\n    pass: secret\n    basic_auth: true\n    login_provider: tacacs\n",
+			errs: []string{
+				`credentials "default": user must be set`,
+				`credentials "lb01": one of pass, pass_file or password_command must be set`,
+				`credentials "lb02": only one of pass, pass_file and password_command may be set`,
+				`credentials "lb03": login_provider cannot be used with basic_auth`,
+			},
+		},
+		{
+			name:   "invalid key",
+			config: "credentials:\n  10.0.0.0/33:\n    user: admin\n    pass: secret\n",
+			errs:   []string{`invalid CIDR credentials key "10.0.0.0/33"`},
+		},
+	}
+	for _, test := range tests {
+		_, err := LoadConfig(writeConfig(t, dir, test.config))
+		if len(test.errs) == 0 {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %s", test.name, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: expected an error", test.name)
+			continue
+		}
+		for _, e := range test.errs {
+			if !strings.Contains(err.Error(), e) {
+				t.Errorf("%s: error %q does not contain %q", test.name, err, e)
+			}
+		}
+	}
+}
+
+func TestReloadConfigKeepsConfigOnError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bigip_exporter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sc := &SafeConfig{C: &Config{}}
+	file := writeConfig(t, dir, "credentials:\n  default:\n    user: admin\n    pass: secret\n")
+	if err := sc.ReloadConfig(file); err != nil {
+		t.Fatal(err)
+	}
+	loaded := sc.C
+
+	writeConfig(t, dir, "credentials:\n  default:\n    user: admin\n    password: other\n")
+	if err := sc.ReloadConfig(file); err == nil {
+		t.Fatal("expected an error reloading an invalid config")
+	}
+	if sc.C != loaded {
+		t.Fatal("invalid config replaced the loaded one")
+	}
+	credentials, err := sc.CredentialsForTarget("lb01.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if credentials.Password != "secret" {
+		t.Errorf("got password %q, want the one of the previous config", credentials.Password)
+	}
+}
+
+func TestRuleForTarget(t *testing.T) {
+	c := &Config{
+		Credentials: map[string]Credentials{
+			"lb01.example.com:443":                    {User: "exact"},
+			"10.0.0.0/8":                              {User: "cidr8"},
+			"10.20.0.0/16":                            {User: "cidr16"},
+			"lb-*.dc1.example:*":                      {User: "glob"},
+			"lb-*-??.dc1.example:*":                   {User: "longer-glob"},
+			"/lb-[a-z]+-\\d+\\.dc2\\.example(:443)?/": {User: "regex"},
+			"default":                                 {User: "default"},
+		},
+	}
+	if err := c.compileRules(); err != nil {
+		t.Fatal(err)
+	}
+	sc := &SafeConfig{C: c}
+
+	tests := []struct {
+		target string
+		key    string
+		kind   string
+	}{
+		{"lb01.example.com:443", "lb01.example.com:443", matchExact},
+		{"lb01.example.com", "default", matchDefault},
+		{"10.20.1.1", "10.20.0.0/16", matchCIDR},
+		{"10.20.1.1:443", "10.20.0.0/16", matchCIDR},
+		{"10.30.1.1", "10.0.0.0/8", matchCIDR},
+		{"[10.30.1.1]:8443", "10.0.0.0/8", matchCIDR},
+		{"192.168.1.1", "default", matchDefault},
+		{"lb-xx-01.dc1.example:443", "lb-*-??.dc1.example:*", matchGlob},
+		{"lb-xx.dc1.example:443", "lb-*.dc1.example:*", matchGlob},
+		{"lb-xx.dc1.example", "default", matchDefault},
+		{"lb-xx-1.dc2.example", "/lb-[a-z]+-\\d+\\.dc2\\.example(:443)?/", matchRegex},
+		{"lb-xx-1.dc2.example:443", "/lb-[a-z]+-\\d+\\.dc2\\.example(:443)?/", matchRegex},
+		{"lb-xx-1.dc2.example.evil", "default", matchDefault},
+	}
+	for _, test := range tests {
+		rule, err := sc.ruleForTarget(test.target)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.target, err)
+			continue
+		}
+		if rule.key != test.key || rule.kind != test.kind {
+			t.Errorf("%s: got rule %q (%s), want %q (%s)", test.target, rule.key, rule.kind, test.key, test.kind)
+		}
+	}
+}
+
+func TestRuleForTargetWithoutDefault(t *testing.T) {
+	c := &Config{
+		Credentials: map[string]Credentials{
+			"lb01.example.com": {User: "exact"},
+		},
+	}
+	if err := c.compileRules(); err != nil {
+		t.Fatal(err)
+	}
+	sc := &SafeConfig{C: c}
+	if _, err := sc.ruleForTarget("lb02.example.com"); err == nil {
+		t.Error("expected an error for a target without matching credentials")
+	}
+}
+
+func TestCompileRulesInvalidKeys(t *testing.T) {
+	for _, key := range []string{"10.0.0.0/33", "/lb-(/", "lb-[.example"} {
+		c := &Config{
+			Credentials: map[string]Credentials{key: {User: "user"}},
+		}
+		if err := c.compileRules(); err == nil {
+			t.Errorf("%s: expected an error", key)
+		}
+	}
+}
+
+func TestCredentialsResolve(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bigip_exporter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	passFile := filepath.Join(dir, "pass")
+	if err := ioutil.WriteFile(passFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("BIGIP_EXPORTER_TEST_USER", "admin")
+	os.Setenv("BIGIP_EXPORTER_TEST_PASS", "from-env")
+	os.Unsetenv("BIGIP_EXPORTER_TEST_UNSET")
+	defer os.Unsetenv("BIGIP_EXPORTER_TEST_USER")
+	defer os.Unsetenv("BIGIP_EXPORTER_TEST_PASS")
+
+	tests := []struct {
+		name        string
+		credentials Credentials
+		user        string
+		password    string
+		err         string
+	}{
+		{
+			name:        "env vars",
+			credentials: Credentials{User: "${BIGIP_EXPORTER_TEST_USER}", Password: "${BIGIP_EXPORTER_TEST_PASS}"},
+			user:        "admin",
+			password:    "from-env",
+		},
+		{
+			name:        "bare dollar",
+			credentials: Credentials{User: "admin", Password: "pa$$word$HOME"},
+			user:        "admin",
+			password:    "pa$$word$HOME",
+		},
+		{
+			name:        "unset env var",
+			credentials: Credentials{User: "admin", Password: "${BIGIP_EXPORTER_TEST_UNSET}"},
+			err:         "unset environment variable BIGIP_EXPORTER_TEST_UNSET",
+		},
+		{
+			name:        "pass_file",
+			credentials: Credentials{User: "admin", PasswordFile: passFile},
+			user:        "admin",
+			password:    "from-file",
+		},
+		{
+			name:        "missing pass_file",
+			credentials: Credentials{User: "admin", PasswordFile: filepath.Join(dir, "missing")},
+			err:         "error reading pass_file",
+		},
+		{
+			name:        "password_command",
+			credentials: Credentials{User: "admin", PasswordCommand: "echo from-command"},
+			user:        "admin",
+			password:    "from-command",
+		},
+		{
+			name:        "failing password_command",
+			credentials: Credentials{User: "admin", PasswordCommand: "echo no vault token >&2; exit 2"},
+			err:         "error running password_command: exit status 2: no vault token",
+		},
+	}
+	for _, test := range tests {
+		credentials := test.credentials
+		err := credentials.resolve()
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%s: got error %v, want %q", test.name, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+		if credentials.User != test.user || credentials.Password != test.password {
+			t.Errorf("%s: got %q/%q, want %q/%q", test.name, credentials.User, credentials.Password, test.user, test.password)
+		}
+	}
+}
+
+func TestPasswordCommandTimeout(t *testing.T) {
+	defer func(timeout time.Duration) { passwordCommandTimeout = timeout }(passwordCommandTimeout)
+	passwordCommandTimeout = 100 * time.Millisecond
+
+	credentials := Credentials{User: "admin", PasswordCommand: "echo hanging >&2; sleep 10; echo late"}
+	start := time.Now()
+	err := credentials.resolve()
+	if err == nil || !strings.Contains(err.Error(), "timed out after 100ms: hanging") {
+		t.Errorf("got error %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("password_command was not stopped, took %s", elapsed)
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/common/log"
+	"golang.org/x/crypto/bcrypt"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// dummyHash is compared against when an unknown user logs in, so that
+// unknown and known users take the same time to be rejected.
+var dummyHash = []byte("$2a$10$q6hmNBiP30t8gPhN3lArx./oNyGr3NG6/FFAV9xkzykle5W8uDbGq")
+
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"":                           tls.NoClientCert,
+	"NoClientCert":               tls.NoClientCert,
+	"RequestClientCert":          tls.RequestClientCert,
+	"RequireAnyClientCert":       tls.RequireAnyClientCert,
+	"VerifyClientCertIfGiven":    tls.VerifyClientCertIfGiven,
+	"RequireAndVerifyClientCert": tls.RequireAndVerifyClientCert,
+}
+
+// WebConfig is the Go representation of the web config file, which secures
+// the HTTP listener of the exporter.
+type WebConfig struct {
+	TLSServerConfig TLSServerConfig    `yaml:"tls_server_config"`
+	BasicAuthUsers  map[string]string  `yaml:"basic_auth_users"`
+	Reload          ReloadRestrictions `yaml:"reload"`
+
+	allowedNetworks []*net.IPNet
+}
+
+// TLSServerConfig is the Go representation of the tls_server_config section
+// in the web config file.
+type TLSServerConfig struct {
+	CertFile       string `yaml:"cert_file"`
+	KeyFile        string `yaml:"key_file"`
+	ClientCAFile   string `yaml:"client_ca_file"`
+	ClientAuthType string `yaml:"client_auth_type"`
+}
+
+// ReloadRestrictions is the Go representation of the reload section in the web
+// config file. It controls access to /-/reload and /-/credentials on top of
+// basic auth.
+type ReloadRestrictions struct {
+	Disabled        bool     `yaml:"disabled"`
+	AllowedUsers    []string `yaml:"allowed_users"`
+	AllowedNetworks []string `yaml:"allowed_networks"`
+}
+
+// LoadWebConfig reads, strictly parses and validates a web config file. An
+// empty file name returns a config without TLS and authentication.
+func LoadWebConfig(webConfigFile string) (*WebConfig, error) {
+	var c = &WebConfig{}
+	if webConfigFile == "" {
+		return c, nil
+	}
+
+	yamlFile, err := ioutil.ReadFile(webConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading web config file: %s", err)
+	}
+
+	if err := yaml.UnmarshalStrict(yamlFile, c); err != nil {
+		return nil, fmt.Errorf("error parsing web config file: %s", err)
+	}
+
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *WebConfig) validate() error {
+	var errs []string
+	t := c.TLSServerConfig
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		errs = append(errs, "tls_server_config: cert_file and key_file must be set together")
+	}
+	if t.CertFile == "" && (t.ClientCAFile != "" || t.ClientAuthType != "") {
+		errs = append(errs, "tls_server_config: client_ca_file and client_auth_type require cert_file and key_file")
+	}
+	if _, ok := clientAuthTypes[t.ClientAuthType]; !ok {
+		errs = append(errs, fmt.Sprintf("tls_server_config: invalid client_auth_type %q", t.ClientAuthType))
+	}
+
+	users := make([]string, 0, len(c.BasicAuthUsers))
+	for user := range c.BasicAuthUsers {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+	for _, user := range users {
+		if _, err := bcrypt.Cost([]byte(c.BasicAuthUsers[user])); err != nil {
+			errs = append(errs, fmt.Sprintf("basic_auth_users: invalid bcrypt hash for user %q: %s", user, err))
+		}
+	}
+
+	for _, user := range c.Reload.AllowedUsers {
+		if _, ok := c.BasicAuthUsers[user]; !ok {
+			errs = append(errs, fmt.Sprintf("reload: allowed user %q is not in basic_auth_users", user))
+		}
+	}
+	for _, cidr := range c.Reload.AllowedNetworks {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("reload: invalid allowed network %q: %s", cidr, err))
+			continue
+		}
+		c.allowedNetworks = append(c.allowedNetworks, network)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid web config:\n  %s", strings.Join(errs, "\n  "))
+	}
+	return nil
+}
+
+// TLSConfig returns the TLS settings of the listener, or nil when TLS is
+// not configured.
+func (c *WebConfig) TLSConfig() (*tls.Config, error) {
+	t := c.TLSServerConfig
+	if t.CertFile == "" {
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("error loading TLS certificate: %s", err)
+	}
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+		ClientAuth:   clientAuthTypes[t.ClientAuthType],
+	}
+	if t.ClientCAFile != "" {
+		pem, err := ioutil.ReadFile(t.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading client CA file: %s", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in client CA file %s", t.ClientCAFile)
+		}
+		cfg.ClientCAs = pool
+		if t.ClientAuthType == "" {
+			cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+	return cfg, nil
+}
+
+// authenticate wraps h so that requests must carry the credentials of one of
+// the basic auth users. Without users every request is let through.
+func (c *WebConfig) authenticate(h http.Handler) http.Handler {
+	if len(c.BasicAuthUsers) == 0 {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if ok {
+			hash, known := c.BasicAuthUsers[user]
+			if !known {
+				hash = string(dummyHash)
+			}
+			if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err == nil && known {
+				h.ServeHTTP(w, r)
+				return
+			}
+		}
+		w.Header().Set("WWW-Authenticate", `Basic realm="bigip_exporter"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	})
+}
+
+// restrictReload wraps the /-/reload and /-/credentials handlers with the
+// checks of the reload section. It must be used behind authenticate.
+func (c *WebConfig) restrictReload(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c.Reload.Disabled {
+			http.Error(w, r.URL.Path+" is disabled", http.StatusForbidden)
+			return
+		}
+		if len(c.Reload.AllowedUsers) > 0 {
+			user, _, _ := r.BasicAuth()
+			if !stringInSlice(user, c.Reload.AllowedUsers) {
+				log.Warnf("Request to %s by user %q from %s refused", r.URL.Path, user, r.RemoteAddr)
+				http.Error(w, "user is not allowed to access "+r.URL.Path, http.StatusForbidden)
+				return
+			}
+		}
+		if len(c.allowedNetworks) > 0 && !c.reloadAllowedFrom(r.RemoteAddr) {
+			log.Warnf("Request to %s from %s refused", r.URL.Path, r.RemoteAddr)
+			http.Error(w, "address is not allowed to access "+r.URL.Path, http.StatusForbidden)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (c *WebConfig) reloadAllowedFrom(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range c.allowedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func stringInSlice(a string, list []string) bool {
+	for _, b := range list {
+		if b == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+func hashPassword(t *testing.T, password string) string {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(hash)
+}
+
+func TestAuthenticate(t *testing.T) {
+	c := &WebConfig{
+		BasicAuthUsers: map[string]string{
+			"prometheus": hashPassword(t, "secret"),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		user     string
+		password string
+		noAuth   bool
+		status   int
+	}{
+		{name: "valid", user: "prometheus", password: "secret", status: http.StatusOK},
+		{name: "wrong password", user: "prometheus", password: "wrong", status: http.StatusUnauthorized},
+		{name: "unknown user", user: "admin", password: "secret", status: http.StatusUnauthorized},
+		{name: "no credentials", noAuth: true, status: http.StatusUnauthorized},
+	}
+	h := c.authenticate(okHandler)
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/bigip", nil)
+		if !test.noAuth {
+			r.SetBasicAuth(test.user, test.password)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != test.status {
+			t.Errorf("%s: got status %d, want %d", test.name, w.Code, test.status)
+		}
+		if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s: missing WWW-Authenticate header", test.name)
+		}
+	}
+}
+
+func TestAuthenticateWithoutUsers(t *testing.T) {
+	c := &WebConfig{}
+	w := httptest.NewRecorder()
+	c.authenticate(okHandler).ServeHTTP(w, httptest.NewRequest("GET", "/bigip", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestRestrictReload(t *testing.T) {
+	_, loopback, _ := net.ParseCIDR("127.0.0.0/8")
+
+	tests := []struct {
+		name       string
+		reload     ReloadRestrictions
+		networks   []*net.IPNet
+		user       string
+		remoteAddr string
+		status     int
+	}{
+		{
+			name:       "unrestricted",
+			remoteAddr: "192.0.2.1:1234",
+			status:     http.StatusOK,
+		},
+		{
+			name:       "disabled",
+			reload:     ReloadRestrictions{Disabled: true},
+			user:       "admin",
+			remoteAddr: "127.0.0.1:1234",
+			status:     http.StatusForbidden,
+		},
+		{
+			name:       "allowed user",
+			reload:     ReloadRestrictions{AllowedUsers: []string{"admin"}},
+			user:       "admin",
+			remoteAddr: "192.0.2.1:1234",
+			status:     http.StatusOK,
+		},
+		{
+			name:       "other user",
+			reload:     ReloadRestrictions{AllowedUsers: []string{"admin"}},
+			user:       "prometheus",
+			remoteAddr: "192.0.2.1:1234",
+			status:     http.StatusForbidden,
+		},
+		{
+			name:       "allowed network",
+			networks:   []*net.IPNet{loopback},
+			remoteAddr: "127.0.0.1:1234",
+			status:     http.StatusOK,
+		},
+		{
+			name:       "other network",
+			networks:   []*net.IPNet{loopback},
+			remoteAddr: "192.0.2.1:1234",
+			status:     http.StatusForbidden,
+		},
+		{
+			name:       "allowed user from other network",
+			reload:     ReloadRestrictions{AllowedUsers: []string{"admin"}},
+			networks:   []*net.IPNet{loopback},
+			user:       "admin",
+			remoteAddr: "192.0.2.1:1234",
+			status:     http.StatusForbidden,
+		},
+	}
+	for _, test := range tests {
+		c := &WebConfig{Reload: test.reload, allowedNetworks: test.networks}
+		r := httptest.NewRequest("POST", "/-/reload", nil)
+		r.RemoteAddr = test.remoteAddr
+		if test.user != "" {
+			r.SetBasicAuth(test.user, "secret")
+		}
+		w := httptest.NewRecorder()
+		c.restrictReload(okHandler).ServeHTTP(w, r)
+		if w.Code != test.status {
+			t.Errorf("%s: got status %d, want %d", test.name, w.Code, test.status)
+		}
+	}
+}
+
+func TestWebConfigValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		c     WebConfig
+		valid bool
+	}{
+		{
+			name:  "empty",
+			valid: true,
+		},
+		{
+			name: "reload restrictions",
+			c: WebConfig{
+				BasicAuthUsers: map[string]string{"admin": hashPassword(t, "secret")},
+				Reload:         ReloadRestrictions{AllowedUsers: []string{"admin"}, AllowedNetworks: []string{"10.0.0.0/8"}},
+			},
+			valid: true,
+		},
+		{
+			name: "invalid hash",
+			c:    WebConfig{BasicAuthUsers: map[string]string{"admin": "secret"}},
+		},
+		{
+			name: "unknown reload user",
+			c:    WebConfig{Reload: ReloadRestrictions{AllowedUsers: []string{"admin"}}},
+		},
+		{
+			name: "invalid reload network",
+			c:    WebConfig{Reload: ReloadRestrictions{AllowedNetworks: []string{"10.0.0.0/33"}}},
+		},
+		{
+			name: "key without cert",
+			c:    WebConfig{TLSServerConfig: TLSServerConfig{KeyFile: "tls.key"}},
+		},
+		{
+			name: "client CA without TLS",
+			c:    WebConfig{TLSServerConfig: TLSServerConfig{ClientCAFile: "ca.crt"}},
+		},
+		{
+			name: "invalid client auth type",
+			c:    WebConfig{TLSServerConfig: TLSServerConfig{CertFile: "tls.crt", KeyFile: "tls.key", ClientAuthType: "Always"}},
+		},
+	}
+	for _, test := range tests {
+		err := test.c.validate()
+		if test.valid && err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}